@@ -18,16 +18,37 @@ import (
 type App struct {
 	ctx             context.Context
 	CurrentLanguage string
+	gateway         *Gateway
 }
 
 var OnConfigChanged func(AppConfig)
 var UpdateTrayMenu func(string)
+var OnKeyHealthChanged func(map[string]string)
 
 type ModelConfig struct {
-	ModelName string `json:"model_name"`
-	ModelUrl  string `json:"model_url"`
-	ApiKey    string `json:"api_key"`
-	IsCustom  bool   `json:"is_custom"`
+	ModelName string       `json:"model_name"`
+	ModelUrl  string       `json:"model_url"`
+	ApiKey    string       `json:"api_key"`
+	ApiKeys   []string     `json:"api_keys"` // Extra keys rotated by the gateway
+	ModelId   string       `json:"model_id"` // Model id sent to the provider, defaults to the preset's or ModelName
+	IsCustom  bool         `json:"is_custom"`
+	IsLocal   bool         `json:"is_local"` // Served by a local server such as Ollama
+	Protocol  string       `json:"protocol"` // "anthropic" (default) or "openai"
+	Budget    BudgetConfig `json:"budget"`
+
+	MaxConcurrency int `json:"max_concurrency"` // In-flight requests allowed through the gateway, 0 for no limit
+	QueueTimeout   int `json:"queue_timeout"`   // Seconds a request may wait for a slot
+
+	// Sampling overrides applied by the gateway
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"` // Ceiling for max_tokens
+
+	Compat  *CompatProfile `json:"compat,omitempty"`  // Overrides the provider preset's profile
+	Network *NetworkConfig `json:"network,omitempty"` // Overrides the global network settings
+
+	Balance     *BalanceSource `json:"balance,omitempty"` // Balance API when the provider preset has none
+	BalanceWarn float64        `json:"balance_warn"`      // Warn when the balance drops below this amount
 }
 
 type ProjectConfig struct {
@@ -35,19 +56,32 @@ type ProjectConfig struct {
 	Name     string `json:"name"`
 	Path     string `json:"path"`
 	YoloMode bool   `json:"yolo_mode"`
+
+	Redactions []RedactionRule `json:"redactions"` // Added to the gateway's global rules
 }
 
 type AppConfig struct {
-	CurrentModel   string          `json:"current_model"`
-	ProjectDir     string          `json:"project_dir"` // Deprecated, kept for migration
-	Models         []ModelConfig   `json:"models"`
-	Projects       []ProjectConfig `json:"projects"`
-	CurrentProject string          `json:"current_project"` // ID of the current project
+	CurrentModel   string           `json:"current_model"`
+	ProjectDir     string           `json:"project_dir"` // Deprecated, kept for migration
+	Models         []ModelConfig    `json:"models"`
+	Projects       []ProjectConfig  `json:"projects"`
+	CurrentProject string           `json:"current_project"` // ID of the current project
+	Network        NetworkConfig    `json:"network"`         // Proxy and CA settings
+	KeyMonitor     KeyMonitorConfig `json:"key_monitor"`     // Background API key validation
+	Node           NodeConfig       `json:"node"`            // Node.js version to install and require
+	Mirrors        MirrorConfig     `json:"mirrors"`         // Download mirrors and npm registry
+	ClaudeCode     ClaudeCodeConfig `json:"claude_code"`     // Claude Code update policy
+	OfflineBundle  string           `json:"offline_bundle"`  // Bundle directory or archive to install from instead of downloading
+	Gateway        GatewayConfig    `json:"gateway"`
+
+	ModelDefaultsImported bool `json:"model_defaults_imported"` // model_config.json has been imported once
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	app := &App{}
+	app.gateway = newGateway(app)
+	return app
 }
 
 // startup is called when the app starts. The context is saved
@@ -59,6 +93,11 @@ func (a *App) startup(ctx context.Context) {
 	// Force sync system env vars using current config on startup
 	config, _ := a.LoadConfig()
 	a.syncToSystemEnv(config)
+	if err := a.gateway.apply(config); err != nil {
+		a.log(err.Error())
+	}
+	go a.runKeyMonitor()
+	go a.runBalanceMonitor()
 }
 
 func (a *App) SetLanguage(lang string) {
@@ -122,40 +161,38 @@ func (a *App) syncToClaudeSettings(config AppConfig) error {
 	settings := make(map[string]interface{})
 	env := make(map[string]string)
 
-	env["ANTHROPIC_AUTH_TOKEN"] = selectedModel.ApiKey
-
-	switch strings.ToLower(selectedModel.ModelName) {
-	case "kimi":
-		env["ANTHROPIC_BASE_URL"] = "https://api.kimi.com/coding"
-		env["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = "kimi-k2-thinking"
-		env["ANTHROPIC_DEFAULT_OPUS_MODEL"] = "kimi-k2-thinking"
-		env["ANTHROPIC_DEFAULT_SONNET_MODEL"] = "kimi-k2-thinking"
-		env["ANTHROPIC_MODEL"] = "kimi-k2-thinking"
-	case "glm", "glm-4.7":
-		env["ANTHROPIC_BASE_URL"] = "https://open.bigmodel.cn/api/anthropic"
-		env["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = "glm-4.7"
-		env["ANTHROPIC_DEFAULT_OPUS_MODEL"] = "glm-4.7"
-		env["ANTHROPIC_DEFAULT_SONNET_MODEL"] = "glm-4.7"
-		env["ANTHROPIC_MODEL"] = "glm-4.7"
-		settings["permissions"] = map[string]string{"defaultMode": "dontAsk"}
-	case "doubao":
-		env["ANTHROPIC_BASE_URL"] = "https://ark.cn-beijing.volces.com/api/coding"
-		env["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = "doubao-seed-code-preview-latest"
-		env["ANTHROPIC_DEFAULT_OPUS_MODEL"] = "doubao-seed-code-preview-latest"
-		env["ANTHROPIC_DEFAULT_SONNET_MODEL"] = "doubao-seed-code-preview-latest"
-		env["ANTHROPIC_MODEL"] = "doubao-seed-code-preview-latest"
-	case "minimax":
-		env["ANTHROPIC_BASE_URL"] = "https://api.minimaxi.com/anthropic"
-		env["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = "MiniMax-M2.1"
-		env["ANTHROPIC_DEFAULT_OPUS_MODEL"] = "MiniMax-M2.1"
-		env["ANTHROPIC_DEFAULT_SONNET_MODEL"] = "MiniMax-M2.1"
-		env["ANTHROPIC_MODEL"] = "MiniMax-M2.1"
-		env["ANTHROPIC_SMALL_FAST_MODEL"] = "MiniMax-M2.1"
-		env["API_TIMEOUT_MS"] = "3000000"
-		env["CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC"] = "1"
-	default:
-		env["ANTHROPIC_BASE_URL"] = selectedModel.ModelUrl
-		env["ANTHROPIC_MODEL"] = selectedModel.ModelName
+	authToken := resolveAuthToken(config, selectedModel)
+	env["ANTHROPIC_AUTH_TOKEN"] = authToken
+
+	modelId := getModelId(selectedModel)
+	env["ANTHROPIC_BASE_URL"] = getBaseUrl(selectedModel)
+	env["ANTHROPIC_MODEL"] = modelId
+	if preset, ok := getProviderPreset(selectedModel); ok {
+		// Built-in providers serve every Claude Code tier with one model
+		env["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = modelId
+		env["ANTHROPIC_DEFAULT_SONNET_MODEL"] = modelId
+		env["ANTHROPIC_DEFAULT_OPUS_MODEL"] = modelId
+		env["ANTHROPIC_SMALL_FAST_MODEL"] = modelId
+		for k, v := range preset.Env {
+			env[k] = v
+		}
+		if preset.DefaultMode != "" {
+			settings["permissions"] = map[string]string{"defaultMode": preset.DefaultMode}
+		}
+	}
+
+	// Route through the local gateway when it is enabled or required
+	if gatewayActive(config) {
+		env["ANTHROPIC_BASE_URL"] = gatewayURL(config.Gateway)
+
+		// With tier routes, hand out placeholders so the gateway can tell tiers apart
+		if len(config.Gateway.Routes) > 0 {
+			delete(env, "ANTHROPIC_MODEL")
+			env["ANTHROPIC_DEFAULT_HAIKU_MODEL"] = tierPlaceholder("haiku")
+			env["ANTHROPIC_DEFAULT_SONNET_MODEL"] = tierPlaceholder("sonnet")
+			env["ANTHROPIC_DEFAULT_OPUS_MODEL"] = tierPlaceholder("opus")
+			env["ANTHROPIC_SMALL_FAST_MODEL"] = tierPlaceholder("haiku")
+		}
 	}
 
 	settings["env"] = env
@@ -181,7 +218,7 @@ func (a *App) syncToClaudeSettings(config AppConfig) error {
 	}
 
 	claudeJson["customApiKeyResponses"] = map[string]interface{}{
-		"approved": []string{selectedModel.ApiKey},
+		"approved": []string{authToken},
 		"rejected": []string{},
 	}
 
@@ -194,25 +231,40 @@ func (a *App) syncToClaudeSettings(config AppConfig) error {
 }
 
 func getBaseUrl(selectedModel *ModelConfig) string {
-	baseUrl := selectedModel.ModelUrl
-	// Match the specific URLs used in settings.json
-	switch strings.ToLower(selectedModel.ModelName) {
-	case "kimi":
-		baseUrl = "https://api.kimi.com/coding"
-	case "glm", "glm-4.7":
-		baseUrl = "https://open.bigmodel.cn/api/anthropic"
-	case "doubao":
-		baseUrl = "https://ark.cn-beijing.volces.com/api/coding"
-	case "minimax":
-		baseUrl = "https://api.minimaxi.com/anthropic"
-	}
-	return baseUrl
+	// Built-in providers always use their known endpoint
+	if preset, ok := getProviderPreset(selectedModel); ok {
+		return preset.BaseUrl
+	}
+	return selectedModel.ModelUrl
+}
+
+// getModelId returns the model id the provider expects. An explicit
+// model_id wins, then the built-in provider's model, then the model name.
+func getModelId(selectedModel *ModelConfig) string {
+	if selectedModel.ModelId != "" {
+		return selectedModel.ModelId
+	}
+	if preset, ok := getProviderPreset(selectedModel); ok {
+		return preset.ModelId
+	}
+	return selectedModel.ModelName
 }
 
 func (a *App) log(message string) {
+	// Without a window, e.g. for --make-bundle, the log goes to the console
+	if a.ctx == nil {
+		fmt.Println(message)
+		return
+	}
 	runtime.EventsEmit(a.ctx, "env-log", message)
 }
 
+// notify raises a desktop notification and forwards it to the frontend.
+func (a *App) notify(title, message string) {
+	runtime.EventsEmit(a.ctx, "notification", map[string]string{"title": title, "message": message})
+	a.showNotification(title, message)
+}
+
 func (a *App) getConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -243,22 +295,22 @@ func (a *App) LoadConfig() (AppConfig, error) {
 			Models: []ModelConfig{
 				{
 					ModelName: "GLM",
-					ModelUrl:  "https://open.bigmodel.cn/api/anthropic",
+					ModelUrl:  providerPresets["glm"].BaseUrl,
 					ApiKey:    "",
 				},
 				{
 					ModelName: "kimi",
-					ModelUrl:  "https://api.kimi.com/coding",
+					ModelUrl:  providerPresets["kimi"].BaseUrl,
 					ApiKey:    "",
 				},
 				{
 					ModelName: "doubao",
-					ModelUrl:  "https://ark.cn-beijing.volces.com/api/coding",
+					ModelUrl:  providerPresets["doubao"].BaseUrl,
 					ApiKey:    "",
 				},
 				{
 					ModelName: "MiniMax",
-					ModelUrl:  "https://api.minimaxi.com/anthropic",
+					ModelUrl:  providerPresets["minimax"].BaseUrl,
 					ApiKey:    "",
 				},
 				{
@@ -272,6 +324,7 @@ func (a *App) LoadConfig() (AppConfig, error) {
 		if len(defaultConfig.Models) > 0 {
 			defaultConfig.CurrentModel = defaultConfig.Models[0].ModelName
 		}
+		importModelDefaults(&defaultConfig)
 
 		err = a.SaveConfig(defaultConfig)
 		return defaultConfig, err
@@ -346,17 +399,8 @@ func (a *App) LoadConfig() (AppConfig, error) {
 			hasCustom = true
 			config.Models[i].IsCustom = true
 		}
-		if config.Models[i].ModelUrl == "" {
-			switch strings.ToLower(config.Models[i].ModelName) {
-			case "glm", "glm-4.7":
-				config.Models[i].ModelUrl = "https://open.bigmodel.cn/api/anthropic"
-			case "kimi":
-				config.Models[i].ModelUrl = "https://api.kimi.com/coding"
-			case "doubao":
-				config.Models[i].ModelUrl = "https://ark.cn-beijing.volces.com/api/coding"
-			case "minimax":
-				config.Models[i].ModelUrl = "https://api.minimaxi.com/anthropic"
-			}
+		if preset, ok := getProviderPreset(&config.Models[i]); ok && config.Models[i].ModelUrl == "" {
+			config.Models[i].ModelUrl = preset.BaseUrl
 		}
 	}
 
@@ -368,7 +412,7 @@ func (a *App) LoadConfig() (AppConfig, error) {
 			if (m.IsCustom || m.ModelName == "Custom") && !inserted {
 				newModels = append(newModels, ModelConfig{
 					ModelName: "MiniMax",
-					ModelUrl:  "https://api.minimaxi.com/anthropic",
+					ModelUrl:  providerPresets["minimax"].BaseUrl,
 					ApiKey:    "your_minimax_api_key_here",
 				})
 				inserted = true
@@ -378,7 +422,7 @@ func (a *App) LoadConfig() (AppConfig, error) {
 		if !inserted {
 			newModels = append(newModels, ModelConfig{
 				ModelName: "MiniMax",
-				ModelUrl:  "https://api.minimaxi.com/anthropic",
+				ModelUrl:  providerPresets["minimax"].BaseUrl,
 				ApiKey:    "your_minimax_api_key_here",
 			})
 		}
@@ -394,14 +438,28 @@ func (a *App) LoadConfig() (AppConfig, error) {
 		})
 	}
 
+	if !config.ModelDefaultsImported {
+		importModelDefaults(&config)
+		if data, err := json.MarshalIndent(config, "", "  "); err == nil {
+			os.WriteFile(path, data, 0644)
+		}
+	}
+
 	return config, nil
 }
 
 func (a *App) SaveConfig(config AppConfig) error {
+	if err := validateRedactionRules(config); err != nil {
+		return err
+	}
 	// Sync to Claude Code settings
 	a.syncToClaudeSettings(config)
 	// Sync system environment variables
 	a.syncToSystemEnv(config)
+	// Start, stop or reconfigure the local gateway
+	if err := a.gateway.apply(config); err != nil {
+		a.log(err.Error())
+	}
 
 	path, err := a.getConfigPath()
 	if err != nil {
@@ -434,7 +492,7 @@ func (a *App) CheckUpdate(currentVersion string) (UpdateResult, error) {
 	}
 	req.Header.Set("User-Agent", "Claude-Code-Easy-Suite")
 
-	client := &http.Client{}
+	client := a.httpClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
 		return UpdateResult{}, err
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveTarget maps an archive entry to a path under root, dropping the
+// first strip path elements. Entries that are dropped entirely map to "",
+// entries that would escape root are rejected.
+func archiveTarget(root, name string, strip int) (string, error) {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' })
+	if len(parts) <= strip {
+		return "", nil
+	}
+	target := filepath.Join(root, filepath.Join(parts[strip:]...))
+	if !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+	return target, nil
+}
+
+func writeArchiveFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, r)
+	return err
+}
+
+// extractZip unpacks a zip archive into destDir, dropping the first strip
+// path elements of every entry.
+func extractZip(zipPath, destDir string, strip int) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	root := filepath.Clean(destDir)
+	for _, f := range r.File {
+		target, err := archiveTarget(root, f.Name, strip)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractZipFile(f, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return writeArchiveFile(target, rc, f.Mode())
+}
+
+// extractTarGz unpacks a .tar.gz archive, such as an npm package, into
+// destDir, dropping the first strip path elements of every entry. Only
+// directories and regular files are extracted.
+func extractTarGz(archivePath, destDir string, strip int) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	root := filepath.Clean(destDir)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveTarget(root, hdr.Name, strip)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(target, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Balance is the remaining credit on the account behind a model's key.
+type Balance struct {
+	ModelName string  `json:"model_name"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	Low       bool    `json:"low"` // Below the model's balance_warn threshold
+	Error     string  `json:"error,omitempty"`
+	CheckedAt string  `json:"checked_at"`
+}
+
+// BalanceFetcher looks up the remaining credit for an API key.
+type BalanceFetcher interface {
+	FetchBalance(ctx context.Context, client *http.Client, key string) (float64, string, error)
+}
+
+// BalanceSource describes a JSON balance endpoint, for providers without a
+// built-in fetcher.
+type BalanceSource struct {
+	Url      string `json:"url"`
+	Field    string `json:"field"` // Dot-separated path to the amount, e.g. "data.available_balance" or "balance_infos.0.total_balance"
+	Currency string `json:"currency"`
+}
+
+// jsonBalanceFetcher reads the amount from a field of a JSON response
+// fetched with the key as bearer token.
+type jsonBalanceFetcher struct {
+	source BalanceSource
+}
+
+func (f jsonBalanceFetcher) FetchBalance(ctx context.Context, client *http.Client, key string) (float64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.source.Url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return 0, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, "", fmt.Errorf("balance request failed with status: %s", resp.Status)
+	}
+	amount, err := parseBalance(data, f.source.Field)
+	return amount, f.source.Currency, err
+}
+
+// parseBalance extracts a numeric amount from a JSON document by path.
+// Array elements are addressed by index and numeric strings are accepted.
+func parseBalance(data []byte, field string) (float64, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0, err
+	}
+	for _, part := range strings.Split(field, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return 0, fmt.Errorf("balance field %q not found", field)
+			}
+			v = node[i]
+		default:
+			return 0, fmt.Errorf("balance field %q not found", field)
+		}
+	}
+	switch amount := v.(type) {
+	case float64:
+		return amount, nil
+	case string:
+		return strconv.ParseFloat(amount, 64)
+	}
+	return 0, fmt.Errorf("balance field %q is not a number", field)
+}
+
+// balanceFetcher returns the model's own balance source, or its provider
+// preset's fetcher when none is configured. It returns nil when neither
+// exists.
+func (m ModelConfig) balanceFetcher() BalanceFetcher {
+	if m.Balance != nil && m.Balance.Url != "" {
+		return jsonBalanceFetcher{source: *m.Balance}
+	}
+	if preset, ok := getProviderPreset(&m); ok {
+		return preset.Balance
+	}
+	return nil
+}
+
+// Minutes between background balance lookups.
+const balanceCheckInterval = 30
+
+// Models that were below their threshold at the last lookup, so the
+// warning is raised once per drop rather than on every refresh.
+var (
+	lowBalanceMu sync.Mutex
+	lowBalance   = make(map[string]bool)
+)
+
+// balanceDropped records whether a model is below its threshold and
+// reports whether it has just dropped below it.
+func balanceDropped(model string, low bool) bool {
+	lowBalanceMu.Lock()
+	defer lowBalanceMu.Unlock()
+	wasLow := lowBalance[model]
+	lowBalance[model] = low
+	return low && !wasLow
+}
+
+// GetBalances looks up the remaining credit for every model with a balance
+// source, warning when it falls below balance_warn. The frontend is also
+// sent the result as a "balances" event.
+func (a *App) GetBalances() []Balance {
+	config, _ := a.LoadConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	balances := []Balance{}
+	for _, m := range config.Models {
+		fetcher := m.balanceFetcher()
+		keys := m.apiKeys()
+		if fetcher == nil || len(keys) == 0 || isPlaceholderKey(keys[0]) {
+			continue
+		}
+		b := Balance{ModelName: m.ModelName, CheckedAt: time.Now().Format(time.RFC3339)}
+		transport, err := sharedTransports.get(effectiveNetwork(config.Network, &m))
+		if err == nil {
+			client := &http.Client{Transport: transport, Timeout: 15 * time.Second}
+			b.Amount, b.Currency, err = fetcher.FetchBalance(ctx, client, keys[0])
+		}
+		if err != nil {
+			b.Error = err.Error()
+			balances = append(balances, b)
+			continue
+		}
+
+		b.Low = m.BalanceWarn > 0 && b.Amount < m.BalanceWarn
+		if balanceDropped(m.ModelName, b.Low) {
+			a.notify("Low balance", fmt.Sprintf("%s has %.2f %s left", m.ModelName, b.Amount, b.Currency))
+		}
+		balances = append(balances, b)
+	}
+
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "balances", balances)
+	}
+	return balances
+}
+
+// runBalanceMonitor refreshes balances in the background, so the low
+// balance warning fires without the window open. The frontend looks them
+// up itself when it loads.
+func (a *App) runBalanceMonitor() {
+	for {
+		time.Sleep(balanceCheckInterval * time.Minute)
+		a.GetBalances()
+	}
+}
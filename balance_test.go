@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetBalancesFromFixtures(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/user/balance":
+			w.Write(readTestdata(t, "balance/deepseek.json"))
+		case "/v1/user/info":
+			w.Write(readTestdata(t, "balance/siliconflow.json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	config := AppConfig{
+		CurrentModel:          "DeepSeek",
+		ModelDefaultsImported: true,
+		Models: []ModelConfig{
+			{ModelName: "DeepSeek", ApiKey: "sk-test", IsCustom: true, BalanceWarn: 100,
+				Balance: &BalanceSource{Url: srv.URL + "/user/balance", Field: "balance_infos.0.total_balance", Currency: "CNY"}},
+			{ModelName: "SiliconFlow", ApiKey: "sk-test",
+				Balance: &BalanceSource{Url: srv.URL + "/v1/user/info", Field: "data.totalBalance", Currency: "CNY"}},
+			{ModelName: "Broken", ApiKey: "sk-test",
+				Balance: &BalanceSource{Url: srv.URL + "/missing", Field: "data.balance"}},
+			// Placeholder and unconfigured models are not looked up
+			{ModelName: "MiniMax", ApiKey: "your_minimax_api_key_here",
+				Balance: &BalanceSource{Url: srv.URL + "/user/balance", Field: "balance_infos.0.total_balance"}},
+			{ModelName: "GLM", ApiKey: "sk-test"},
+		},
+	}
+	data, _ := json.Marshal(config)
+	if err := os.WriteFile(filepath.Join(home, ".claude_model_config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := NewApp().GetBalances()
+	if len(got) != 3 {
+		t.Fatalf("balances = %+v, want DeepSeek, SiliconFlow and Broken", got)
+	}
+	if got[0].ModelName != "DeepSeek" || got[0].Amount != 110 || got[0].Currency != "CNY" || got[0].Low {
+		t.Errorf("DeepSeek = %+v", got[0])
+	}
+	if got[1].ModelName != "SiliconFlow" || got[1].Amount != 88.88 || got[1].Error != "" {
+		t.Errorf("SiliconFlow = %+v", got[1])
+	}
+	if got[2].ModelName != "Broken" || got[2].Error == "" {
+		t.Errorf("Broken = %+v, want an error", got[2])
+	}
+}
+
+func TestPresetBalanceFetcher(t *testing.T) {
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != "https://api.moonshot.cn/v1/users/me/balance" {
+			t.Errorf("url = %s", req.URL)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Body:       io.NopCloser(bytes.NewReader(readTestdata(t, "balance/moonshot.json"))),
+		}, nil
+	})}
+
+	f := (ModelConfig{ModelName: "Kimi"}).balanceFetcher()
+	if f == nil {
+		t.Fatal("kimi has no balance fetcher")
+	}
+	amount, currency, err := f.FetchBalance(context.Background(), client, "sk-test")
+	if err != nil || amount != 49.58894 || currency != "CNY" {
+		t.Errorf("kimi balance = %v %s, %v", amount, currency, err)
+	}
+
+	// A configured source replaces the preset's
+	own := ModelConfig{ModelName: "kimi", Balance: &BalanceSource{Url: "https://example.com/balance", Field: "total"}}
+	if f, ok := own.balanceFetcher().(jsonBalanceFetcher); !ok || f.source.Url != "https://example.com/balance" {
+		t.Errorf("configured source ignored: %+v", own.balanceFetcher())
+	}
+	if f := (ModelConfig{ModelName: "GLM"}).balanceFetcher(); f != nil {
+		t.Errorf("GLM fetcher = %+v, want none", f)
+	}
+}
+
+func TestBalanceDroppedWarnsOnce(t *testing.T) {
+	model := "warn-once-" + t.Name()
+	steps := []struct {
+		low  bool
+		want bool
+	}{
+		{false, false},
+		{true, true},
+		{true, false}, // Still low: no second warning
+		{false, false},
+		{true, true}, // Topped up and spent again
+	}
+	for i, step := range steps {
+		if got := balanceDropped(model, step.low); got != step.want {
+			t.Errorf("step %d: balanceDropped(%v) = %v, want %v", i, step.low, got, step.want)
+		}
+	}
+}
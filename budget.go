@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultBudgetWarnPercent = 80
+
+// BudgetConfig caps what a model may consume through the local gateway.
+// A zero limit means unlimited. Prices are per million tokens and are only
+// needed for the cost limits.
+type BudgetConfig struct {
+	DailyTokens   int64   `json:"daily_tokens"`
+	MonthlyTokens int64   `json:"monthly_tokens"`
+	DailyCost     float64 `json:"daily_cost"`
+	MonthlyCost   float64 `json:"monthly_cost"`
+	InputPrice    float64 `json:"input_price"`
+	OutputPrice   float64 `json:"output_price"`
+	WarnPercent   int     `json:"warn_percent"`
+}
+
+func (b BudgetConfig) isSet() bool {
+	return b.DailyTokens > 0 || b.MonthlyTokens > 0 || b.DailyCost > 0 || b.MonthlyCost > 0
+}
+
+func (b BudgetConfig) warnPercent() int {
+	if b.WarnPercent <= 0 || b.WarnPercent > 100 {
+		return defaultBudgetWarnPercent
+	}
+	return b.WarnPercent
+}
+
+// ModelUsage is the running total for one model in the current day and month.
+type ModelUsage struct {
+	ModelName   string          `json:"model_name"`
+	Day         string          `json:"day"`
+	DayTokens   int64           `json:"day_tokens"`
+	DayCost     float64         `json:"day_cost"`
+	Month       string          `json:"month"`
+	MonthTokens int64           `json:"month_tokens"`
+	MonthCost   float64         `json:"month_cost"`
+	Warned      map[string]bool `json:"warned"`
+}
+
+// rollover resets the counters when the day or month has changed.
+func (u *ModelUsage) rollover(now time.Time) {
+	day := now.Format("2006-01-02")
+	month := now.Format("2006-01")
+	if u.Day != day {
+		u.Day = day
+		u.DayTokens = 0
+		u.DayCost = 0
+		for k := range u.Warned {
+			if strings.HasPrefix(k, "daily") {
+				delete(u.Warned, k)
+			}
+		}
+	}
+	if u.Month != month {
+		u.Month = month
+		u.MonthTokens = 0
+		u.MonthCost = 0
+		for k := range u.Warned {
+			if strings.HasPrefix(k, "monthly") {
+				delete(u.Warned, k)
+			}
+		}
+	}
+	if u.Warned == nil {
+		u.Warned = make(map[string]bool)
+	}
+}
+
+type budgetLimit struct {
+	key   string
+	label string
+	used  float64
+	limit float64
+}
+
+func (u *ModelUsage) limits(b BudgetConfig) []budgetLimit {
+	return []budgetLimit{
+		{"daily_tokens", "daily token", float64(u.DayTokens), float64(b.DailyTokens)},
+		{"monthly_tokens", "monthly token", float64(u.MonthTokens), float64(b.MonthlyTokens)},
+		{"daily_cost", "daily cost", u.DayCost, b.DailyCost},
+		{"monthly_cost", "monthly cost", u.MonthCost, b.MonthlyCost},
+	}
+}
+
+// tokenUsage is the subset of the Messages API usage object the gateway counts.
+type tokenUsage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+func (t tokenUsage) input() int64 {
+	return t.InputTokens + t.CacheCreationInputTokens + t.CacheReadInputTokens
+}
+
+func (t tokenUsage) total() int64 {
+	return t.input() + t.OutputTokens
+}
+
+// exchangeUsage reads the usage reported by the provider, either from the
+// JSON body or from the message_start/message_delta events of a stream.
+func exchangeUsage(ex *exchange) (tokenUsage, bool) {
+	var usage tokenUsage
+	if ex.status != 200 {
+		return usage, false
+	}
+	if ex.stream {
+		message, err := reassembleMessage(ex.events)
+		if err != nil {
+			return usage, false
+		}
+		data, _ := json.Marshal(message["usage"])
+		if json.Unmarshal(data, &usage) != nil {
+			return usage, false
+		}
+	} else {
+		var resp struct {
+			Usage *tokenUsage `json:"usage"`
+		}
+		if json.Unmarshal(ex.respBody.Bytes(), &resp) != nil || resp.Usage == nil {
+			return usage, false
+		}
+		usage = *resp.Usage
+	}
+	return usage, usage.total() > 0
+}
+
+type budgetLedger struct {
+	mu     sync.Mutex
+	loaded bool
+	usage  map[string]*ModelUsage
+}
+
+func getUsagePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cceasy", "usage.json"), nil
+}
+
+func (l *budgetLedger) load() {
+	if l.loaded {
+		return
+	}
+	l.loaded = true
+	l.usage = make(map[string]*ModelUsage)
+	path, err := getUsagePath()
+	if err != nil {
+		return
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &l.usage)
+	}
+}
+
+func (l *budgetLedger) save() error {
+	path, err := getUsagePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l.usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (l *budgetLedger) get(modelName string, now time.Time) *ModelUsage {
+	l.load()
+	u, ok := l.usage[modelName]
+	if !ok {
+		u = &ModelUsage{ModelName: modelName}
+		l.usage[modelName] = u
+	}
+	u.rollover(now)
+	return u
+}
+
+// check returns an error when the model has already reached one of its caps.
+func (l *budgetLedger) check(model ModelConfig) error {
+	if !model.Budget.isSet() {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.get(model.ModelName, time.Now())
+	for _, lim := range u.limits(model.Budget) {
+		if lim.limit > 0 && lim.used >= lim.limit {
+			return fmt.Errorf("cceasy budget exceeded for %s: %s limit of %s reached", model.ModelName, lim.label, formatBudgetValue(lim.key, lim.limit))
+		}
+	}
+	return nil
+}
+
+// record adds usage to the model's totals and returns a warning for every
+// threshold crossed for the first time in the current period.
+func (l *budgetLedger) record(model ModelConfig, usage tokenUsage) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.get(model.ModelName, time.Now())
+	cost := (float64(usage.input())*model.Budget.InputPrice + float64(usage.OutputTokens)*model.Budget.OutputPrice) / 1e6
+	u.DayTokens += usage.total()
+	u.MonthTokens += usage.total()
+	u.DayCost += cost
+	u.MonthCost += cost
+
+	var warnings []string
+	warnAt := float64(model.Budget.warnPercent()) / 100
+	for _, lim := range u.limits(model.Budget) {
+		if lim.limit <= 0 || u.Warned[lim.key] || lim.used < lim.limit*warnAt {
+			continue
+		}
+		u.Warned[lim.key] = true
+		warnings = append(warnings, fmt.Sprintf("%s has used %.0f%% of its %s limit (%s of %s).",
+			model.ModelName, lim.used/lim.limit*100, lim.label, formatBudgetValue(lim.key, lim.used), formatBudgetValue(lim.key, lim.limit)))
+	}
+	return warnings, l.save()
+}
+
+func (l *budgetLedger) snapshot() []ModelUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.load()
+	result := []ModelUsage{}
+	for name := range l.usage {
+		result = append(result, *l.get(name, now))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ModelName < result[j].ModelName
+	})
+	return result
+}
+
+func (l *budgetLedger) reset(modelName string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.load()
+	delete(l.usage, modelName)
+	return l.save()
+}
+
+func formatBudgetValue(key string, v float64) string {
+	if strings.HasSuffix(key, "_cost") {
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprintf("%.0f tokens", v)
+}
+
+// GetUsage returns the gateway's token and cost totals per model.
+func (a *App) GetUsage() []ModelUsage {
+	return a.gateway.budgets.snapshot()
+}
+
+// ResetUsage clears the recorded totals for a model.
+func (a *App) ResetUsage(modelName string) error {
+	return a.gateway.budgets.reset(modelName)
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	bundleManifestName = "manifest.json"
+	bundleFormat       = 1
+)
+
+// BundleManifest describes an offline bundle: the Node.js archive and the
+// Claude Code package it holds and the platform they are built for.
+type BundleManifest struct {
+	Format     int        `json:"format"`
+	CreatedAt  string     `json:"created_at"`
+	Os         string     `json:"os"`             // GOOS the bundle installs on, e.g. "linux"
+	Arch       string     `json:"arch"`           // GOARCH the bundle installs on, e.g. "amd64"
+	Libc       string     `json:"libc,omitempty"` // "musl" for Alpine and other musl-based Linux
+	Node       BundleFile `json:"node"`
+	ClaudeCode BundleFile `json:"claude_code"` // npm pack tarball with its dependencies bundled
+}
+
+// BundleFile is one file of an offline bundle.
+type BundleFile struct {
+	Version string `json:"version"`
+	File    string `json:"file"` // Name inside the bundle directory
+	Sha256  string `json:"sha256"`
+}
+
+// offlineBundle is an opened bundle whose files have been verified.
+type offlineBundle struct {
+	dir      string
+	manifest BundleManifest
+	temp     string // Extraction directory of an archived bundle
+}
+
+func (b *offlineBundle) path(f BundleFile) string {
+	return filepath.Join(b.dir, f.File)
+}
+
+func (b *offlineBundle) close() {
+	if b.temp != "" {
+		os.RemoveAll(b.temp)
+	}
+}
+
+// openBundle reads a bundle from a directory or a .zip/.tar.gz archive of
+// one and checks its files against the manifest.
+func openBundle(path string) (*offlineBundle, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &offlineBundle{dir: path}
+	if !info.IsDir() {
+		temp, err := os.MkdirTemp("", "cceasy-bundle-*")
+		if err != nil {
+			return nil, err
+		}
+		b.temp = temp
+		lower := strings.ToLower(path)
+		switch {
+		case strings.HasSuffix(lower, ".zip"):
+			err = extractZip(path, temp, 0)
+		case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+			err = extractTarGz(path, temp, 0)
+		default:
+			err = fmt.Errorf("unsupported bundle archive %s, expected .zip or .tar.gz", filepath.Base(path))
+		}
+		if err != nil {
+			b.close()
+			return nil, err
+		}
+		b.dir = bundleRoot(temp)
+	}
+	if err := b.load(); err != nil {
+		b.close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// bundleRoot finds the manifest in an extracted archive, which may hold the
+// bundle directory itself or only its contents.
+func bundleRoot(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, bundleManifestName)); err == nil {
+		return dir
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name())
+	}
+	return dir
+}
+
+func (b *offlineBundle) load() error {
+	data, err := os.ReadFile(filepath.Join(b.dir, bundleManifestName))
+	if err != nil {
+		return fmt.Errorf("bundle manifest not found: %w", err)
+	}
+	if err := json.Unmarshal(data, &b.manifest); err != nil {
+		return fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	m := b.manifest
+	if m.Format != bundleFormat {
+		return fmt.Errorf("unsupported bundle format %d", m.Format)
+	}
+	if m.Os != runtime.GOOS || m.Arch != runtime.GOARCH {
+		return fmt.Errorf("the bundle is for %s/%s, this machine is %s/%s", m.Os, m.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+	if (m.Libc == libcMusl) != (detectLibc() == libcMusl) {
+		return fmt.Errorf("the bundle is for %s, this machine uses %s", libcName(m.Libc), libcName(detectLibc()))
+	}
+	for _, f := range []BundleFile{m.Node, m.ClaudeCode} {
+		if f.File == "" || f.Sha256 == "" || filepath.Base(f.File) != f.File {
+			return fmt.Errorf("invalid bundle manifest entry %q", f.File)
+		}
+		actual, err := fileSHA256(b.path(f))
+		if err != nil {
+			return fmt.Errorf("bundle file missing: %w", err)
+		}
+		if !strings.EqualFold(actual, f.Sha256) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", f.File, f.Sha256, actual)
+		}
+	}
+	return nil
+}
+
+func libcName(libc string) string {
+	if libc == libcMusl {
+		return libcMusl
+	}
+	return libcGlibc
+}
+
+// offlineBundle opens the bundle set in the config. It returns nil when
+// none is set.
+func (a *App) offlineBundle() (*offlineBundle, error) {
+	config, _ := a.LoadConfig()
+	path := strings.TrimSpace(config.OfflineBundle)
+	if path == "" {
+		return nil, nil
+	}
+	b, err := openBundle(path)
+	if err != nil {
+		return nil, fmt.Errorf("offline bundle %s is unusable: %w", path, err)
+	}
+	a.log("Using offline bundle " + path)
+	return b, nil
+}
+
+// installNodeFromBundle installs Node.js into destDir from the configured
+// offline bundle. It reports false when no bundle is set, in which case
+// Node.js is downloaded as usual.
+func (a *App) installNodeFromBundle(destDir string) (bool, error) {
+	b, err := a.offlineBundle()
+	if err != nil {
+		return true, err
+	}
+	if b == nil {
+		return false, nil
+	}
+	defer b.close()
+
+	a.log(fmt.Sprintf("Installing Node.js v%s from the offline bundle...", b.manifest.Node.Version))
+	return true, a.extractNodeArchive(b.path(b.manifest.Node), destDir)
+}
+
+// installClaudeFromBundle installs the Claude Code version of the
+// configured offline bundle unless it is already installed. It reports
+// false when no bundle is set. The update policy does not apply offline.
+func (a *App) installClaudeFromBundle(npm string, installed bool) bool {
+	b, err := a.offlineBundle()
+	if err != nil {
+		a.log(err.Error())
+		return true
+	}
+	if b == nil {
+		return false
+	}
+	defer b.close()
+
+	version := b.manifest.ClaudeCode.Version
+	if installed && claudeVersion(npm) == version {
+		a.log("Claude Code " + version + " from the offline bundle is installed.")
+		return true
+	}
+	a.log("Installing Claude Code " + version + " from the offline bundle...")
+	args := []string{"install", "-g", b.path(b.manifest.ClaudeCode), "--offline"}
+	if err := a.installClaudePackage(npm, args); err != nil {
+		a.log("Installation failed: " + err.Error())
+	} else {
+		a.log("Claude Code " + version + " installed.")
+	}
+	return true
+}
+
+// CheckOfflineBundle verifies a bundle directory or archive and returns
+// its manifest.
+func (a *App) CheckOfflineBundle(path string) (BundleManifest, error) {
+	b, err := openBundle(strings.TrimSpace(path))
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	defer b.close()
+	return b.manifest, nil
+}
+
+// npmPlatforms and npmArchs map Go names to the ones npm uses to select
+// optional dependencies.
+var (
+	npmPlatforms = map[string]string{"windows": "win32"}
+	npmArchs     = map[string]string{"amd64": "x64", "386": "ia32", "ppc64le": "ppc64"}
+)
+
+func npmTarget(goos, goarch string) (string, string) {
+	platform, arch := goos, goarch
+	if p, ok := npmPlatforms[goos]; ok {
+		platform = p
+	}
+	if a, ok := npmArchs[goarch]; ok {
+		arch = a
+	}
+	return platform, arch
+}
+
+// npmIn runs npm in dir and returns its standard output.
+func npmIn(dir, npm string, args ...string) ([]byte, error) {
+	cmd := exec.Command(npm, args...)
+	cmd.Dir = dir
+	hideCommandWindow(cmd)
+	out, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return out, fmt.Errorf("npm %s failed: %v: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return out, err
+}
+
+// packedFile reads the tarball name from the output of `npm pack --json`.
+func packedFile(out []byte) (string, error) {
+	var packed []struct {
+		Filename string `json:"filename"`
+	}
+	if err := json.Unmarshal(out, &packed); err != nil || len(packed) == 0 || packed[0].Filename == "" {
+		return "", fmt.Errorf("unexpected npm pack output: %s", strings.TrimSpace(string(out)))
+	}
+	// Scoped packages are reported as "@scope/name-1.0.0.tgz" by some npm versions
+	return strings.TrimPrefix(strings.ReplaceAll(packed[0].Filename, "/", "-"), "@"), nil
+}
+
+// bundleDependencies marks every installed dependency of the package in
+// dir as bundled, so `npm pack` puts them into the tarball. It also drops
+// the scripts `npm pack` runs, which --ignore-scripts does not skip for a
+// directory.
+func bundleDependencies(dir string) error {
+	manifestPath := filepath.Join(dir, "package.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+	var pkg map[string]interface{}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return err
+	}
+
+	installed := map[string]bool{}
+	for _, field := range []string{"dependencies", "optionalDependencies"} {
+		deps, _ := pkg[field].(map[string]interface{})
+		for name := range deps {
+			// Optional dependencies for other platforms are not installed
+			if _, err := os.Stat(filepath.Join(dir, "node_modules", filepath.FromSlash(name))); err == nil {
+				installed[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(installed))
+	for name := range installed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pkg["bundleDependencies"] = names
+	if scripts, ok := pkg["scripts"].(map[string]interface{}); ok {
+		for _, name := range []string{"prepack", "prepare", "postpack"} {
+			delete(scripts, name)
+		}
+	}
+
+	data, err = json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, data, 0644)
+}
+
+// packClaude writes an npm tarball of Claude Code into destDir that
+// carries its dependencies for the target platform, so it installs
+// without a registry. It returns the tarball name.
+func (a *App) packClaude(npm, version, goos, goarch, libc, destDir string) (string, error) {
+	config, _ := a.LoadConfig()
+	work, err := os.MkdirTemp("", "cceasy-pack-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(work)
+
+	args := append([]string{"pack", claudePackage + "@" + version, "--json", "--ignore-scripts"}, npmRegistryArgs(config)...)
+	out, err := npmIn(work, npm, args...)
+	if err != nil {
+		return "", err
+	}
+	tarball, err := packedFile(out)
+	if err != nil {
+		return "", err
+	}
+	pkgDir := filepath.Join(work, "package")
+	if err := extractTarGz(filepath.Join(work, tarball), pkgDir, 1); err != nil {
+		return "", fmt.Errorf("failed to unpack %s: %w", tarball, err)
+	}
+
+	platform, arch := npmTarget(goos, goarch)
+	args = []string{"install", "--omit=dev", "--ignore-scripts", "--no-package-lock", "--os=" + platform, "--cpu=" + arch}
+	if libc != "" {
+		args = append(args, "--libc="+libc)
+	}
+	args = append(args, npmRegistryArgs(config)...)
+	if _, err := npmIn(pkgDir, npm, args...); err != nil {
+		return "", err
+	}
+	if err := bundleDependencies(pkgDir); err != nil {
+		return "", err
+	}
+
+	out, err = npmIn(pkgDir, npm, "pack", "--json", "--ignore-scripts", "--pack-destination", destDir)
+	if err != nil {
+		return "", err
+	}
+	return packedFile(out)
+}
+
+// CreateOfflineBundle downloads the configured Node.js version and packs
+// Claude Code with its dependencies into a bundle directory under
+// outputDir, for machines without internet access. targetOs is a GOOS
+// value or "linux-musl" for musl-based Linux. Empty targetOs and
+// targetArch select this machine's platform. It returns the bundle
+// directory.
+func (a *App) CreateOfflineBundle(outputDir, targetOs, targetArch string) (string, error) {
+	libc := ""
+	if targetOs == "" {
+		targetOs = runtime.GOOS
+		if detectLibc() == libcMusl {
+			libc = libcMusl
+		}
+	} else if goos, variant, ok := strings.Cut(targetOs, "-"); ok && goos == "linux" && variant == libcMusl {
+		targetOs, libc = goos, libcMusl
+	}
+	if targetArch == "" {
+		targetArch = runtime.GOARCH
+	}
+	config, err := a.LoadConfig()
+	if err != nil {
+		return "", err
+	}
+	npm := findNpm()
+	if npm == "" {
+		return "", fmt.Errorf("npm not found")
+	}
+
+	nodeVersion := config.Node.targetVersion()
+	nodeFile, err := nodeDistFile(nodeVersion, targetOs, targetArch, libc)
+	if err != nil {
+		return "", err
+	}
+	version := ""
+	if config.ClaudeCode.policy() == ClaudePolicyPinned {
+		version = config.ClaudeCode.Version
+	}
+	if version == "" {
+		if _, version, err = a.fetchClaudeVersions(); err != nil {
+			return "", fmt.Errorf("failed to look up the latest Claude Code version: %w", err)
+		}
+	}
+	if !npmVersionPattern.MatchString(version) {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+
+	target := targetOs
+	if libc != "" {
+		target += "-" + libc
+	}
+	dir := filepath.Join(outputDir, fmt.Sprintf("cceasy-bundle-%s-%s", target, targetArch))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	a.log(fmt.Sprintf("Downloading Node.js v%s for %s/%s...", nodeVersion, target, targetArch))
+	nodePath := filepath.Join(dir, nodeFile)
+	url, err := a.download(nodePath, a.nodeDownloadURLs(nodeVersion, nodeFile))
+	if err != nil {
+		return "", err
+	}
+	if err := a.verifyNodeDownload(nodePath, nodeVersion, nodeFile, url); err != nil {
+		return "", err
+	}
+
+	a.log(fmt.Sprintf("Packing Claude Code %s with its dependencies...", version))
+	claudeFile, err := a.packClaude(npm, version, targetOs, targetArch, libc, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack Claude Code: %w", err)
+	}
+
+	manifest := BundleManifest{
+		Format:     bundleFormat,
+		CreatedAt:  time.Now().Format(time.RFC3339),
+		Os:         targetOs,
+		Arch:       targetArch,
+		Libc:       libc,
+		Node:       BundleFile{Version: nodeVersion, File: nodeFile},
+		ClaudeCode: BundleFile{Version: version, File: claudeFile},
+	}
+	for _, f := range []*BundleFile{&manifest.Node, &manifest.ClaudeCode} {
+		if f.Sha256, err = fileSHA256(filepath.Join(dir, f.File)); err != nil {
+			return "", err
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, bundleManifestName), data, 0644); err != nil {
+		return "", err
+	}
+	a.log("Offline bundle created in " + dir)
+	return dir, nil
+}
+
+// makeBundleCommand implements `--make-bundle <dir> [--os <os>] [--arch
+// <arch>]`, which creates an offline bundle without opening a window, and
+// returns the process exit code.
+func makeBundleCommand(a *App, args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("--make-bundle", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	targetOs := flags.String("os", "", `target OS, e.g. "windows", "darwin", "linux" or "linux-musl" (default: this machine)`)
+	targetArch := flags.String("arch", "", `target architecture, e.g. "amd64" or "arm64" (default: this machine)`)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: cceasy --make-bundle <dir> [--os <os>] [--arch <arch>]")
+		flags.PrintDefaults()
+	}
+
+	// The directory may come before or after the options
+	var dir string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		dir, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if dir == "" && flags.NArg() > 0 {
+		dir = flags.Arg(0)
+		flags.Parse(flags.Args()[1:])
+	}
+	if dir == "" || flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	bundle, err := a.CreateOfflineBundle(dir, *targetOs, *targetArch)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	fmt.Println(bundle)
+	return 0
+}
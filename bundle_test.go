@@ -0,0 +1,282 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestArchiveTarget(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "root")
+	tests := []struct {
+		name  string
+		strip int
+		want  string // Relative to root; "" when dropped
+		err   bool
+	}{
+		{name: "bin/node", want: "bin/node"},
+		{name: "node-v22/bin/node", strip: 1, want: "bin/node"},
+		{name: `node-v22\bin\node.exe`, strip: 1, want: "bin/node.exe"},
+		{name: "node-v22/", strip: 1},
+		{name: "../escape.txt", err: true},
+		{name: "bundle/../../escape.txt", err: true},
+		{name: "node-v22/../../escape.txt", strip: 1, err: true},
+		{name: "..", err: true},
+	}
+	for _, tt := range tests {
+		got, err := archiveTarget(root, tt.name, tt.strip)
+		if tt.err {
+			if err == nil {
+				t.Errorf("archiveTarget(%q, %d) = %q, want an error", tt.name, tt.strip, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("archiveTarget(%q, %d): %v", tt.name, tt.strip, err)
+			continue
+		}
+		want := ""
+		if tt.want != "" {
+			want = filepath.Join(root, filepath.FromSlash(tt.want))
+		}
+		if got != want {
+			t.Errorf("archiveTarget(%q, %d) = %q, want %q", tt.name, tt.strip, got, want)
+		}
+	}
+}
+
+func TestExtractRejectsTraversal(t *testing.T) {
+	for _, name := range []string{"traversal.zip", "traversal.tar.gz"} {
+		parent := t.TempDir()
+		dest := filepath.Join(parent, "out")
+		archive := filepath.Join("testdata", "bundle", name)
+		var err error
+		if strings.HasSuffix(name, ".zip") {
+			err = extractZip(archive, dest, 0)
+		} else {
+			err = extractTarGz(archive, dest, 0)
+		}
+		if err == nil || !strings.Contains(err.Error(), "illegal path") {
+			t.Errorf("%s: err = %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(parent, "escape.txt")); !os.IsNotExist(err) {
+			t.Errorf("%s: file written outside the destination", name)
+		}
+		if _, err := openBundle(archive); err == nil {
+			t.Errorf("%s: bundle opened", name)
+		}
+	}
+}
+
+func TestBundleRoot(t *testing.T) {
+	flat := t.TempDir()
+	os.WriteFile(filepath.Join(flat, bundleManifestName), []byte("{}"), 0644)
+	os.Mkdir(filepath.Join(flat, "extra"), 0755)
+	if got := bundleRoot(flat); got != flat {
+		t.Errorf("manifest at the top: %s", got)
+	}
+
+	nested := t.TempDir()
+	os.Mkdir(filepath.Join(nested, "cceasy-bundle-linux-amd64"), 0755)
+	if got := bundleRoot(nested); got != filepath.Join(nested, "cceasy-bundle-linux-amd64") {
+		t.Errorf("single directory: %s", got)
+	}
+
+	ambiguous := t.TempDir()
+	os.Mkdir(filepath.Join(ambiguous, "a"), 0755)
+	os.Mkdir(filepath.Join(ambiguous, "b"), 0755)
+	if got := bundleRoot(ambiguous); got != ambiguous {
+		t.Errorf("several directories: %s", got)
+	}
+}
+
+func TestPackedFile(t *testing.T) {
+	got, err := packedFile(readTestdata(t, "bundle/npm-pack.json"))
+	if err != nil || got != "anthropic-ai-claude-code-2.0.1.tgz" {
+		t.Errorf("packedFile = %q, %v", got, err)
+	}
+	got, err = packedFile([]byte(`[{"filename":"@anthropic-ai/claude-code-2.0.1.tgz"}]`))
+	if err != nil || got != "anthropic-ai-claude-code-2.0.1.tgz" {
+		t.Errorf("scoped filename = %q, %v", got, err)
+	}
+	for _, out := range []string{"", "[]", `[{"id":"x"}]`, "npm ERR! 404"} {
+		if _, err := packedFile([]byte(out)); err == nil {
+			t.Errorf("packedFile(%q) succeeded", out)
+		}
+	}
+}
+
+// newTestBundle writes a bundle for goos/goarch into dir and returns its
+// manifest.
+func newTestBundle(t *testing.T, dir, goos, goarch string) BundleManifest {
+	t.Helper()
+	os.MkdirAll(dir, 0755)
+	m := BundleManifest{
+		Format:     bundleFormat,
+		Os:         goos,
+		Arch:       goarch,
+		Libc:       detectLibc(),
+		Node:       BundleFile{Version: "22.14.0", File: "node-v22.14.0.tar.gz"},
+		ClaudeCode: BundleFile{Version: "2.0.1", File: "anthropic-ai-claude-code-2.0.1.tgz"},
+	}
+	for _, f := range []*BundleFile{&m.Node, &m.ClaudeCode} {
+		path := filepath.Join(dir, f.File)
+		if err := os.WriteFile(path, []byte("fixture "+f.File), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f.Sha256, _ = fileSHA256(path)
+	}
+	writeTestManifest(t, dir, m)
+	return m
+}
+
+func writeTestManifest(t *testing.T, dir string, m BundleManifest) {
+	t.Helper()
+	data, _ := json.Marshal(m)
+	if err := os.WriteFile(filepath.Join(dir, bundleManifestName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// archiveTestBundle packs the files of dir under a top-level directory
+// into a .zip or .tar.gz archive.
+func archiveTestBundle(t *testing.T, dir, archive string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	var buf bytes.Buffer
+	if strings.HasSuffix(archive, ".zip") {
+		zw := zip.NewWriter(&buf)
+		for _, e := range entries {
+			data, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+			w, _ := zw.Create("cceasy-bundle/" + e.Name())
+			w.Write(data)
+		}
+		zw.Close()
+	} else {
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for _, e := range entries {
+			data, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+			tw.WriteHeader(&tar.Header{Name: "cceasy-bundle/" + e.Name(), Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+			tw.Write(data)
+		}
+		tw.Close()
+		gz.Close()
+	}
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenBundle(t *testing.T) {
+	dir := t.TempDir()
+	bundleDir := filepath.Join(dir, "bundle")
+	want := newTestBundle(t, bundleDir, runtime.GOOS, runtime.GOARCH)
+
+	for _, path := range []string{bundleDir, filepath.Join(dir, "bundle.zip"), filepath.Join(dir, "bundle.tar.gz")} {
+		if path != bundleDir {
+			archiveTestBundle(t, bundleDir, path)
+		}
+		b, err := openBundle(path)
+		if err != nil {
+			t.Fatalf("%s: %v", filepath.Base(path), err)
+		}
+		if b.manifest.ClaudeCode != want.ClaudeCode || b.manifest.Node != want.Node {
+			t.Errorf("%s: manifest = %+v", filepath.Base(path), b.manifest)
+		}
+		if _, err := os.Stat(b.path(b.manifest.Node)); err != nil {
+			t.Errorf("%s: %v", filepath.Base(path), err)
+		}
+		b.close()
+		if b.temp != "" {
+			if _, err := os.Stat(b.temp); !os.IsNotExist(err) {
+				t.Errorf("%s: extraction directory left behind", filepath.Base(path))
+			}
+		}
+	}
+
+	if _, err := openBundle(filepath.Join(dir, "bundle.rar")); err == nil {
+		t.Error("opened a missing bundle")
+	}
+	os.WriteFile(filepath.Join(dir, "bundle.rar"), []byte("rar"), 0644)
+	if _, err := openBundle(filepath.Join(dir, "bundle.rar")); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("rar archive: %v", err)
+	}
+}
+
+func TestBundleManifestVerification(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(dir string, m *BundleManifest)
+		err    string
+	}{
+		{"tampered file", func(dir string, m *BundleManifest) {
+			os.WriteFile(filepath.Join(dir, m.ClaudeCode.File), []byte("tampered"), 0644)
+		}, "checksum mismatch"},
+		{"missing file", func(dir string, m *BundleManifest) {
+			os.Remove(filepath.Join(dir, m.Node.File))
+		}, "bundle file missing"},
+		{"path in file name", func(dir string, m *BundleManifest) {
+			m.Node.File = "../" + m.Node.File
+		}, "invalid bundle manifest entry"},
+		{"no checksum", func(dir string, m *BundleManifest) {
+			m.ClaudeCode.Sha256 = ""
+		}, "invalid bundle manifest entry"},
+		{"other platform", func(dir string, m *BundleManifest) {
+			m.Os, m.Arch = "plan9", "mips"
+		}, "plan9/mips"},
+		{"other libc", func(dir string, m *BundleManifest) {
+			if m.Libc == libcMusl {
+				m.Libc = ""
+			} else {
+				m.Libc = libcMusl
+			}
+		}, "the bundle is for"},
+		{"newer format", func(dir string, m *BundleManifest) {
+			m.Format = bundleFormat + 1
+		}, "unsupported bundle format"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		m := newTestBundle(t, dir, runtime.GOOS, runtime.GOARCH)
+		tt.change(dir, &m)
+		writeTestManifest(t, dir, m)
+		if _, err := openBundle(dir); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, bundleManifestName), []byte("not json"), 0644)
+	if _, err := openBundle(dir); err == nil || !strings.Contains(err.Error(), "invalid bundle manifest") {
+		t.Errorf("bad manifest: %v", err)
+	}
+	if _, err := openBundle(t.TempDir()); err == nil || !strings.Contains(err.Error(), "manifest not found") {
+		t.Errorf("no manifest: %v", err)
+	}
+}
+
+func TestMakeBundleCommandUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"--os", "linux"},
+		{"out", "extra"},
+		{"out", "--os"},
+		{"out", "--unknown"},
+	} {
+		var stderr bytes.Buffer
+		if code := makeBundleCommand(NewApp(), args, &stderr); code != 2 {
+			t.Errorf("%q: exit code %d", args, code)
+		}
+		if !strings.Contains(stderr.String(), "Usage: cceasy --make-bundle") {
+			t.Errorf("%q: no usage in %q", args, stderr.String())
+		}
+	}
+}
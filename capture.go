@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redactedValue = "[REDACTED]"
+
+// CaptureRecord is one request/response exchange written by the gateway
+// when capture mode is on. Streamed responses are stored reassembled.
+type CaptureRecord struct {
+	Id              string            `json:"id"`
+	Time            string            `json:"time"`
+	Session         string            `json:"session"`
+	Provider        string            `json:"provider"`
+	Method          string            `json:"method"`
+	Path            string            `json:"path"`
+	Status          int               `json:"status"`
+	DurationMs      int64             `json:"duration_ms"`
+	Stream          bool              `json:"stream"`
+	RequestHeaders  map[string]string `json:"request_headers"`
+	Request         interface{}       `json:"request"`
+	ResponseHeaders map[string]string `json:"response_headers"`
+	Response        interface{}       `json:"response"`
+	Error           string            `json:"error,omitempty"`
+}
+
+type CaptureSession struct {
+	Id      string `json:"id"`
+	Records int    `json:"records"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"mod_time"`
+}
+
+var captureNamePattern = regexp.MustCompile(`[^0-9A-Za-z_-]`)
+
+func getCaptureDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cceasy", "captures"), nil
+}
+
+// captureSessionPath maps a session id to its JSONL file, refusing anything
+// that could escape the capture directory.
+func captureSessionPath(session string) (string, error) {
+	dir, err := getCaptureDir()
+	if err != nil {
+		return "", err
+	}
+	name := captureNamePattern.ReplaceAllString(session, "_")
+	if name == "" {
+		return "", fmt.Errorf("invalid capture session id")
+	}
+	return filepath.Join(dir, name+".jsonl"), nil
+}
+
+type captureWriter struct {
+	mu sync.Mutex
+}
+
+func (c *captureWriter) write(ex *exchange) error {
+	record := CaptureRecord{
+		Id:              ex.id,
+		Time:            ex.start.Format(time.RFC3339),
+		Session:         ex.session,
+		Provider:        ex.model.ModelName,
+		Method:          ex.method,
+		Path:            ex.path,
+		Status:          ex.status,
+		DurationMs:      time.Since(ex.start).Milliseconds(),
+		Stream:          ex.stream,
+		RequestHeaders:  redactHeaders(ex.reqHeader),
+		Request:         captureBody(ex.reqBody),
+		ResponseHeaders: redactHeaders(ex.respHeader),
+	}
+	if ex.stream {
+		if message, err := reassembleMessage(ex.events); err == nil {
+			record.Response = message
+		} else {
+			record.Response = ex.events
+		}
+	} else {
+		record.Response = captureBody(ex.respBody.Bytes())
+	}
+	if ex.err != nil {
+		record.Error = ex.err.Error()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// Keys may also be echoed back in bodies or error messages
+	for _, key := range ex.model.apiKeys() {
+		line = []byte(strings.ReplaceAll(string(line), key, redactedValue))
+	}
+
+	path, err := captureSessionPath(ex.session)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func redactHeaders(h map[string][]string) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		switch strings.ToLower(k) {
+		case "authorization", "x-api-key", "proxy-authorization", "cookie", "set-cookie":
+			out[k] = redactedValue
+		default:
+			out[k] = strings.Join(v, ", ")
+		}
+	}
+	return out
+}
+
+// captureBody keeps JSON bodies structured and falls back to plain text.
+func captureBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		return v
+	}
+	return string(body)
+}
+
+func (a *App) ListCaptureSessions() ([]CaptureSession, error) {
+	dir, err := getCaptureDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []CaptureSession{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []CaptureSession{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(e.Name(), ".jsonl")
+		records, _ := readCaptureSession(filepath.Join(dir, e.Name()))
+		sessions = append(sessions, CaptureSession{
+			Id:      id,
+			Records: len(records),
+			Size:    info.Size(),
+			ModTime: info.ModTime().Format(time.RFC3339),
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ModTime > sessions[j].ModTime
+	})
+	return sessions, nil
+}
+
+func (a *App) LoadCaptureSession(id string) ([]CaptureRecord, error) {
+	path, err := captureSessionPath(id)
+	if err != nil {
+		return nil, err
+	}
+	return readCaptureSession(path)
+}
+
+func (a *App) DeleteCaptureSession(id string) error {
+	path, err := captureSessionPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DiffCaptureRecords compares two captured exchanges, typically the same
+// prompt sent to two providers, and returns a line diff of their JSON.
+func (a *App) DiffCaptureRecords(sessionA, idA, sessionB, idB string) (string, error) {
+	recordA, err := findCaptureRecord(sessionA, idA)
+	if err != nil {
+		return "", err
+	}
+	recordB, err := findCaptureRecord(sessionB, idB)
+	if err != nil {
+		return "", err
+	}
+
+	textA, err := captureDiffText(recordA)
+	if err != nil {
+		return "", err
+	}
+	textB, err := captureDiffText(recordB)
+	if err != nil {
+		return "", err
+	}
+
+	header := fmt.Sprintf("--- %s/%s (%s)\n+++ %s/%s (%s)\n", sessionA, idA, recordA.Provider, sessionB, idB, recordB.Provider)
+	return header + diffLines(strings.Split(textA, "\n"), strings.Split(textB, "\n")), nil
+}
+
+func readCaptureSession(path string) ([]CaptureRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []CaptureRecord{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var r CaptureRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+func findCaptureRecord(session, id string) (CaptureRecord, error) {
+	path, err := captureSessionPath(session)
+	if err != nil {
+		return CaptureRecord{}, err
+	}
+	records, err := readCaptureSession(path)
+	if err != nil {
+		return CaptureRecord{}, err
+	}
+	for _, r := range records {
+		if r.Id == id {
+			return r, nil
+		}
+	}
+	return CaptureRecord{}, fmt.Errorf("capture record %s not found in session %s", id, session)
+}
+
+// captureDiffText renders the parts of a record worth comparing across
+// providers; ids, timings and headers always differ and only add noise.
+func captureDiffText(r CaptureRecord) (string, error) {
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"status":   r.Status,
+		"request":  r.Request,
+		"response": r.Response,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// diffLines produces a minimal line diff using the longest common
+// subsequence. Common prefixes and suffixes are trimmed first so large
+// requests that differ in a few places stay cheap.
+func diffLines(a, b []string) string {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var sb strings.Builder
+	for _, l := range a[:prefix] {
+		sb.WriteString("  " + l + "\n")
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	if len(midA)*len(midB) > 4000000 {
+		// Too large for the quadratic table; show the blocks as replaced
+		for _, l := range midA {
+			sb.WriteString("- " + l + "\n")
+		}
+		for _, l := range midB {
+			sb.WriteString("+ " + l + "\n")
+		}
+	} else {
+		lcs := make([][]int, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(midA) && j < len(midB) {
+			switch {
+			case midA[i] == midB[j]:
+				sb.WriteString("  " + midA[i] + "\n")
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				sb.WriteString("- " + midA[i] + "\n")
+				i++
+			default:
+				sb.WriteString("+ " + midB[j] + "\n")
+				j++
+			}
+		}
+		for ; i < len(midA); i++ {
+			sb.WriteString("- " + midA[i] + "\n")
+		}
+		for ; j < len(midB); j++ {
+			sb.WriteString("+ " + midB[j] + "\n")
+		}
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		sb.WriteString("  " + l + "\n")
+	}
+	return sb.String()
+}
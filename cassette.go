@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// Response headers worth keeping in a cassette; the rest is connection noise.
+var cassetteHeaders = []string{"Content-Type", "Retry-After", "Request-Id"}
+
+// cassetteEntry is one recorded exchange. The request is kept, with its
+// credentials redacted, so a cassette can be inspected and its hashes
+// understood without the original session.
+type cassetteEntry struct {
+	Hash          string              `json:"hash"`
+	Method        string              `json:"method"`
+	Url           string              `json:"url"`
+	RequestHeader map[string]string   `json:"request_header"`
+	RequestBody   string              `json:"request_body"`
+	Status        int                 `json:"status"`
+	Header        map[string][]string `json:"header"`
+	Body          string              `json:"body"`
+	RecordedAt    string              `json:"recorded_at"`
+}
+
+// Cassette stores upstream request/response pairs in a JSONL file and
+// serves them back by request hash, so provider behaviour can be reproduced
+// without network access.
+type Cassette struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string][]cassetteEntry
+	next    map[string]int
+}
+
+func defaultCassettePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cceasy", "cassettes", "default.jsonl"), nil
+}
+
+func openCassette(path string) *Cassette {
+	return &Cassette{path: path}
+}
+
+func (c *Cassette) load() error {
+	if c.loaded {
+		return nil
+	}
+	c.entries = make(map[string][]cassetteEntry)
+	c.next = make(map[string]int)
+
+	f, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		c.loaded = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var e cassetteEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		c.entries[e.Hash] = append(c.entries[e.Hash], e)
+	}
+	c.loaded = true
+	return scanner.Err()
+}
+
+// requestHash identifies a request by method, URL and body. Credentials are
+// ignored and the body's metadata, which carries the session id, is dropped
+// so a replay in a new session still matches.
+func requestHash(method, url string, body []byte) string {
+	canonical := body
+	var v map[string]interface{}
+	if json.Unmarshal(body, &v) == nil {
+		delete(v, "metadata")
+		if data, err := json.Marshal(v); err == nil {
+			canonical = data
+		}
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", method, url)
+	h.Write(canonical)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay returns the recorded response for req. Repeated identical requests
+// are answered in recorded order, staying on the last one when exhausted.
+func (c *Cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	hash := requestHash(req.Method, req.URL.String(), body)
+	entries := c.entries[hash]
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, req.URL.Path, c.path)
+	}
+	i := c.next[hash]
+	if i < len(entries)-1 {
+		c.next[hash] = i + 1
+	}
+	e := entries[i]
+
+	header := make(http.Header)
+	for k, vv := range e.Header {
+		header[k] = vv
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *Cassette) record(e cassetteEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return err
+	}
+	c.entries[e.Hash] = append(c.entries[e.Hash], e)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// cassetteTransport records or replays requests depending on mode. In
+// record mode responses still stream through; the entry is written once
+// the body has been fully read.
+type cassetteTransport struct {
+	cassette *Cassette
+	mode     string
+	next     http.RoundTripper
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if t.mode == CassetteReplay {
+		return t.cassette.replay(req, body)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || t.mode != CassetteRecord {
+		return resp, err
+	}
+
+	header := make(map[string][]string)
+	for _, k := range cassetteHeaders {
+		if v := resp.Header.Values(k); len(v) > 0 {
+			header[k] = v
+		}
+	}
+	entry := cassetteEntry{
+		Hash:          requestHash(req.Method, req.URL.String(), body),
+		Method:        req.Method,
+		Url:           req.URL.String(),
+		RequestHeader: redactHeaders(req.Header),
+		RequestBody:   string(body),
+		Status:        resp.StatusCode,
+		Header:        header,
+		RecordedAt:    time.Now().Format(time.RFC3339),
+	}
+	resp.Body = &recordingBody{ReadCloser: resp.Body, onDone: func(data []byte) {
+		entry.Body = string(data)
+		t.cassette.record(entry)
+	}}
+	return resp, nil
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	buf    bytes.Buffer
+	done   bool
+	onDone func([]byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF && !b.done {
+		b.done = true
+		b.onDone(b.buf.Bytes())
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	// Responses abandoned halfway are not recorded; replaying them would be misleading
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	upstream := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}, "Cf-Ray": {"noise"}},
+			Body:       io.NopCloser(strings.NewReader(`{"ok":true}`)),
+			Request:    req,
+		}, nil
+	})
+	recorder := &cassetteTransport{cassette: openCassette(path), mode: CassetteRecord, next: upstream}
+
+	body := `{"model":"m","metadata":{"user_id":"user_x_session_1"}}`
+	req, _ := http.NewRequest("POST", "https://api.example.com/v1/messages", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("X-Api-Key", "sk-secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("sk-secret")) {
+		t.Fatalf("cassette contains the API key: %s", data)
+	}
+	var entry cassetteEntry
+	if err := json.Unmarshal(bytes.TrimSpace(data), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.RequestBody != body {
+		t.Errorf("request body = %q, want %q", entry.RequestBody, body)
+	}
+	if entry.RequestHeader["Authorization"] != redactedValue || entry.RequestHeader["X-Api-Key"] != redactedValue {
+		t.Errorf("credentials not redacted: %v", entry.RequestHeader)
+	}
+	if entry.RequestHeader["Content-Type"] != "application/json" {
+		t.Errorf("request headers = %v", entry.RequestHeader)
+	}
+	if _, ok := entry.Header["Cf-Ray"]; ok {
+		t.Errorf("response header noise recorded: %v", entry.Header)
+	}
+
+	// A fresh cassette replays from the file; a new session id still matches
+	player := &cassetteTransport{cassette: openCassette(path), mode: CassetteReplay, next: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("replay reached the upstream")
+		return nil, nil
+	})}
+	req, _ = http.NewRequest("POST", "https://api.example.com/v1/messages", strings.NewReader(`{"model":"m","metadata":{"user_id":"user_x_session_2"}}`))
+	resp, err = player.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(replayed) != `{"ok":true}` {
+		t.Errorf("replayed %d %s", resp.StatusCode, replayed)
+	}
+
+	req, _ = http.NewRequest("POST", "https://api.example.com/v1/messages", strings.NewReader(`{"model":"other"}`))
+	if _, err := player.RoundTrip(req); err == nil {
+		t.Error("unrecorded request was answered")
+	}
+}
+
+func TestGatewayReplayWithoutKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	hits := 0
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}]}`)
+	}))
+	defer up.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	model := ModelConfig{ModelName: "Custom", ModelUrl: up.URL, ApiKey: "sk-secret", IsCustom: true}
+	config := AppConfig{
+		CurrentModel: "Custom",
+		Models:       []ModelConfig{model},
+		Gateway:      GatewayConfig{CassetteMode: CassetteRecord, Cassette: path},
+	}
+	serve := func(g *Gateway) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		body := `{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hello"}]}`
+		g.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/messages", strings.NewReader(body)))
+		return rec
+	}
+
+	g := newGateway(nil)
+	if err := g.apply(config); err != nil {
+		t.Fatal(err)
+	}
+	recorded := serve(g)
+	if recorded.Code != http.StatusOK {
+		t.Fatalf("record: %d %s", recorded.Code, recorded.Body)
+	}
+	up.Close()
+
+	// Replay offline with no API key configured at all
+	config.Models[0].ApiKey = ""
+	config.Gateway.CassetteMode = CassetteReplay
+	g = newGateway(nil)
+	if err := g.apply(config); err != nil {
+		t.Fatal(err)
+	}
+	replayed := serve(g)
+	if replayed.Code != http.StatusOK || replayed.Body.String() != recorded.Body.String() {
+		t.Errorf("replay: %d %s, want %s", replayed.Code, replayed.Body, recorded.Body)
+	}
+	if hits != 1 {
+		t.Errorf("upstream hit %d times, want 1", hits)
+	}
+}
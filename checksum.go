@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Official Node.js release directory, preferred for SHASUMS256.txt so a
+// mirror cannot vouch for its own files.
+const nodeDistURL = "https://nodejs.org/dist"
+
+// Pinned SHA-256 of Git for Windows installers by file name, copied from
+// the release notes when gitVersion is bumped. An installer without a pin
+// is never run.
+var gitInstallerSHA256 = map[string]string{}
+
+// parseShasums finds the hash for fileName in a SHASUMS256.txt listing.
+func parseShasums(data []byte, fileName string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks binary mode with a leading '*'
+		if strings.TrimPrefix(fields[1], "*") == fileName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum listed for %s", fileName)
+}
+
+func fileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyFile checks a download against its expected SHA-256 and deletes it
+// on mismatch, so a tampered or truncated file is never used.
+func verifyFile(filePath, expected string) error {
+	actual, err := fileSHA256(filePath)
+	if err != nil {
+		os.Remove(filePath)
+		return fmt.Errorf("failed to hash %s: %w", filePath, err)
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		os.Remove(filePath)
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path.Base(filePath), expected, actual)
+	}
+	return nil
+}
+
+// fetchText downloads a small text file such as a checksum listing.
+func (a *App) fetchText(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cceasy")
+	resp, err := a.httpClient(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request for %s failed with status: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+}
+
+// nodeChecksumURLs lists where SHASUMS256.txt for a Node.js release file
+// is fetched from. Only the official release directory is trusted unless
+// trustMirror allows the listing next to a mirrored download.
+func nodeChecksumURLs(version, fileName, downloadURL string, trustMirror bool) []string {
+	origin := nodeDistURL
+	if nodeUnofficialBuild(fileName) {
+		origin = nodeUnofficialURL
+	}
+	urls := []string{fmt.Sprintf("%s/v%s/SHASUMS256.txt", origin, version)}
+	if !trustMirror {
+		return urls
+	}
+	if dir := strings.TrimSuffix(downloadURL, fileName); dir != downloadURL {
+		if mirror := dir + "SHASUMS256.txt"; mirror != urls[0] {
+			urls = append(urls, mirror)
+		}
+	}
+	return urls
+}
+
+// nodeChecksum looks up a Node.js release file in SHASUMS256.txt. A mirror's
+// own listing could vouch for a tampered file, so it is only consulted with
+// mirrors.trust_mirror_checksums set.
+func (a *App) nodeChecksum(version, fileName, downloadURL string) (string, error) {
+	config, _ := a.LoadConfig()
+	urls := nodeChecksumURLs(version, fileName, downloadURL, config.Mirrors.TrustMirrorChecksums)
+
+	var lastErr error
+	for _, u := range urls {
+		data, err := a.fetchText(u)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return parseShasums(data, fileName)
+	}
+	if len(urls) == 1 {
+		return "", fmt.Errorf("failed to fetch SHASUMS256.txt from nodejs.org, set mirrors.trust_mirror_checksums to accept the mirror's own listing: %w", lastErr)
+	}
+	return "", fmt.Errorf("failed to fetch SHASUMS256.txt: %w", lastErr)
+}
+
+// verifyNodeDownload checks a downloaded Node.js file before it is
+// extracted or executed.
+func (a *App) verifyNodeDownload(filePath, version, fileName, downloadURL string) error {
+	expected, err := a.nodeChecksum(version, fileName, downloadURL)
+	if err != nil {
+		os.Remove(filePath)
+		return err
+	}
+	if err := verifyFile(filePath, expected); err != nil {
+		return err
+	}
+	a.log("Checksum verified for " + fileName)
+	return nil
+}
+
+// gitChecksum returns the pinned hash for a Git for Windows installer.
+func gitChecksum(fileName string) (string, error) {
+	sum, ok := gitInstallerSHA256[fileName]
+	if !ok || sum == "" {
+		return "", fmt.Errorf("no pinned checksum for %s, install Git for Windows from https://git-scm.com/download/win and retry", fileName)
+	}
+	return sum, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testShasums = "a1f2e3d4c5b6a7980112233445566778899aabbccddeeff00112233445566778  node-v22.14.0-darwin-arm64.tar.gz\n" +
+	"B2F2E3D4C5B6A7980112233445566778899AABBCCDDEEFF00112233445566778 *node-v22.14.0-win-x64.zip\n" +
+	"c3f2e3d4c5b6a7980112233445566778899aabbccddeeff00112233445566778  node-v22.14.0-linux-x64.tar.xz\r\n" +
+	"d4f2e3d4c5b6a7980112233445566778899aabbccddeeff00112233445566778  node-v22.14.0-linux-x64.tar.xz.sig\r\n"
+
+func TestParseShasums(t *testing.T) {
+	tests := []struct {
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"node-v22.14.0-darwin-arm64.tar.gz", "a1f2e3d4c5b6a7980112233445566778899aabbccddeeff00112233445566778", false},
+		// Binary mode marker and upper-case hex
+		{"node-v22.14.0-win-x64.zip", "b2f2e3d4c5b6a7980112233445566778899aabbccddeeff00112233445566778", false},
+		// CRLF line endings, and a longer name sharing the prefix
+		{"node-v22.14.0-linux-x64.tar.xz", "c3f2e3d4c5b6a7980112233445566778899aabbccddeeff00112233445566778", false},
+		{"node-v22.14.0-linux-arm64.tar.xz", "", true},
+		{"node-v22.14.0", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseShasums([]byte(testShasums), tt.file)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseShasums(%q) = %q, %v; want %q", tt.file, got, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	// SHA-256 of "hello\n"
+	const sum = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	write := func() string {
+		path := filepath.Join(t.TempDir(), "node.tar.gz")
+		if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	path := write()
+	if err := verifyFile(path, strings.ToUpper(sum)+"\n"); err != nil {
+		t.Errorf("matching file rejected: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("verified file removed: %v", err)
+	}
+
+	path = write()
+	err := verifyFile(path, strings.Repeat("0", 64))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("mismatch error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("file with a wrong checksum was kept")
+	}
+
+	if err := verifyFile(filepath.Join(t.TempDir(), "missing"), sum); err == nil {
+		t.Error("missing file verified")
+	}
+}
+
+func TestNodeChecksumURLs(t *testing.T) {
+	const file = "node-v22.14.0-linux-x64.tar.xz"
+	mirror := "https://npmmirror.com/mirrors/node/v22.14.0/" + file
+
+	official := nodeChecksumURLs("22.14.0", file, mirror, false)
+	if len(official) != 1 || official[0] != "https://nodejs.org/dist/v22.14.0/SHASUMS256.txt" {
+		t.Errorf("without opt-in = %v, want only nodejs.org", official)
+	}
+	trusted := nodeChecksumURLs("22.14.0", file, mirror, true)
+	if len(trusted) != 2 || trusted[1] != "https://npmmirror.com/mirrors/node/v22.14.0/SHASUMS256.txt" {
+		t.Errorf("with opt-in = %v", trusted)
+	}
+	musl := nodeChecksumURLs("22.14.0", "node-v22.14.0-linux-x64-musl.tar.xz", "", true)
+	if len(musl) != 1 || !strings.HasPrefix(musl[0], nodeUnofficialURL) {
+		t.Errorf("musl = %v", musl)
+	}
+}
+
+func TestGitChecksumRequiresPin(t *testing.T) {
+	if _, err := gitChecksum("Git-0.0.0-64-bit.exe"); err == nil {
+		t.Error("installer without a pinned checksum accepted")
+	}
+}
+
+func TestGitInstallerPinned(t *testing.T) {
+	sum, err := gitChecksum(gitInstallerName)
+	if err != nil {
+		t.Fatalf("%v; add its SHA-256 from the Git for Windows release notes to gitInstallerSHA256", err)
+	}
+	if len(sum) != 64 || strings.Trim(sum, "0123456789abcdef") != "" {
+		t.Errorf("checksum for %s = %q, want 64 lower-case hex digits", gitInstallerName, sum)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	stdruntime "runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const claudePackage = "@anthropic-ai/claude-code"
+
+const (
+	ClaudePolicyAuto   = "auto"   // Install new releases during the environment check
+	ClaudePolicyNotify = "notify" // Tell the user about new releases, install nothing
+	ClaudePolicyPinned = "pinned" // Keep the configured version installed
+)
+
+// ClaudeCodeConfig decides how the environment check keeps Claude Code up
+// to date.
+type ClaudeCodeConfig struct {
+	Policy  string `json:"policy"`  // "auto", "notify" or "pinned"; empty for the platform default
+	Version string `json:"version"` // Version kept by the pinned policy
+}
+
+// defaultClaudePolicy keeps what each platform did before the policy
+// existed: Windows always installed the latest release, Linux and macOS
+// never updated.
+func defaultClaudePolicy(goos string) string {
+	if goos == "windows" {
+		return ClaudePolicyAuto
+	}
+	return ClaudePolicyNotify
+}
+
+func (c ClaudeCodeConfig) policy() string {
+	switch c.Policy {
+	case ClaudePolicyAuto, ClaudePolicyNotify, ClaudePolicyPinned:
+		return c.Policy
+	}
+	return defaultClaudePolicy(stdruntime.GOOS)
+}
+
+// Versions are passed to npm and, on macOS, into a shell script.
+var npmVersionPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z.+\-]*$`)
+
+func getClaudeStatePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cceasy", "claude_code.json"), nil
+}
+
+// claudeState remembers the version replaced by the last install, so it
+// can be rolled back to, and the last release the user was told about.
+type claudeState struct {
+	Previous string `json:"previous"`
+	Notified string `json:"notified"`
+}
+
+func loadClaudeState() claudeState {
+	var state claudeState
+	if path, err := getClaudeStatePath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &state)
+		}
+	}
+	return state
+}
+
+func saveClaudeState(state claudeState) error {
+	path, err := getClaudeStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// claudeVersion asks npm which version of Claude Code is installed in the
+// prefix it is managed in. It returns "" when there is none.
+func claudeVersion(npm string) string {
+	return claudeVersionIn(npm, claudePrefixArgs(npm))
+}
+
+func claudeVersionIn(npm string, prefixArgs []string) string {
+	cmd := exec.Command(npm, append([]string{"ls", "-g", claudePackage, "--json", "--depth=0"}, prefixArgs...)...)
+	hideCommandWindow(cmd)
+	// npm ls exits non-zero for unrelated problems in the tree but still prints the listing
+	out, _ := cmd.Output()
+	var listing struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if json.Unmarshal(out, &listing) != nil {
+		return ""
+	}
+	return listing.Dependencies[claudePackage].Version
+}
+
+// fetchClaudeVersions reads the published versions, newest first, and the
+// latest tag from the configured npm registry.
+func (a *App) fetchClaudeVersions() ([]string, string, error) {
+	config, _ := a.LoadConfig()
+	registry := strings.TrimRight(strings.TrimSpace(config.Mirrors.NpmRegistry), "/")
+	if registry == "" {
+		registry = npmRegistries[0]
+	}
+
+	req, err := http.NewRequest("GET", registry+"/"+strings.Replace(claudePackage, "/", "%2f", 1), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	// The abbreviated document lists versions without their READMEs
+	req.Header.Set("Accept", "application/vnd.npm.install-v1+json")
+	resp, err := a.httpClient(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("registry request failed with status: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024*1024))
+	if err != nil {
+		return nil, "", err
+	}
+	return parseRegistryVersions(data)
+}
+
+func parseRegistryVersions(data []byte) ([]string, string, error) {
+	var doc struct {
+		DistTags map[string]string          `json:"dist-tags"`
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, "", err
+	}
+	versions := make([]string, 0, len(doc.Versions))
+	for v := range doc.Versions {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if c := compareVersions(versions[i], versions[j]); c != 0 {
+			return c > 0
+		}
+		return versions[i] > versions[j]
+	})
+	return versions, doc.DistTags["latest"], nil
+}
+
+// installClaude installs the given version, or the latest when empty, and
+// remembers the version it replaced.
+func (a *App) installClaude(npm, version string) error {
+	if version != "" && !npmVersionPattern.MatchString(version) {
+		return fmt.Errorf("invalid version %q", version)
+	}
+	return a.installClaudePackage(npm, a.claudeInstallArgs(version))
+}
+
+// installClaudePackage runs an npm install of Claude Code into the prefix
+// it is managed in and remembers the version it replaced.
+func (a *App) installClaudePackage(npm string, installArgs []string) error {
+	prefixArgs := claudePrefixArgs(npm)
+	previous := claudeVersionIn(npm, prefixArgs)
+
+	args := append(installArgs, prefixArgs...)
+	if len(prefixArgs) > 0 {
+		a.log("The global npm prefix is not writable. Using " + getUserNpmPrefix())
+		if err := os.MkdirAll(getUserNpmPrefix(), 0755); err != nil {
+			return err
+		}
+	}
+	a.log("Running command: " + npm + " " + strings.Join(args, " "))
+	if out, err := a.runNpm(npm, args); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	if len(prefixArgs) > 0 {
+		prependPath(getUserNpmBinDir())
+	}
+
+	if current := claudeVersionIn(npm, prefixArgs); previous != "" && previous != current {
+		state := loadClaudeState()
+		state.Previous = previous
+		if err := saveClaudeState(state); err != nil {
+			a.log("Failed to record the previous Claude Code version: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// ensureClaudeCode installs or updates Claude Code as the configured policy
+// says. It is called by the environment check once npm has been found.
+func (a *App) ensureClaudeCode(npm string, installed bool) {
+	if a.installClaudeFromBundle(npm, installed) {
+		return
+	}
+
+	config, _ := a.LoadConfig()
+	cc := config.ClaudeCode
+
+	if !installed {
+		version := ""
+		if cc.policy() == ClaudePolicyPinned {
+			version = cc.Version
+		}
+		a.log("Claude Code not found. Installing...")
+		if err := a.installClaude(npm, version); err != nil {
+			a.log("Installation failed: " + err.Error())
+		} else {
+			a.log("Claude Code installed.")
+		}
+		return
+	}
+
+	current := claudeVersion(npm)
+	if current == "" && !userPrefixHasClaude() {
+		if global := claudeVersionIn(npm, nil); global != "" {
+			// Updates will go to the per-user prefix and shadow this copy
+			current = global
+			a.log("Claude Code is installed in the global npm prefix, which is not writable. It can be migrated to " + getUserNpmPrefix() + ".")
+			if a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "claude-migration-available", getUserNpmPrefix())
+			}
+		}
+	}
+	if cc.policy() == ClaudePolicyPinned {
+		if cc.Version == "" || cc.Version == current {
+			a.log("Claude Code " + current + " is installed.")
+			return
+		}
+		a.log(fmt.Sprintf("Claude Code %s is installed, but %s is pinned. Installing...", current, cc.Version))
+		if err := a.installClaude(npm, cc.Version); err != nil {
+			a.log("Failed to install Claude Code " + cc.Version + ": " + err.Error())
+		} else {
+			a.log("Claude Code " + cc.Version + " installed.")
+		}
+		return
+	}
+
+	a.log("Claude Code found. Checking for updates...")
+	_, latest, err := a.fetchClaudeVersions()
+	if err != nil {
+		a.log("Failed to check for Claude Code updates: " + err.Error())
+		return
+	}
+	if latest == "" || (current != "" && compareVersions(latest, current) <= 0) {
+		a.log("Claude Code " + current + " is up to date.")
+		return
+	}
+
+	if cc.policy() == ClaudePolicyAuto {
+		a.log(fmt.Sprintf("Updating Claude Code from %s to %s...", current, latest))
+		if err := a.installClaude(npm, latest); err != nil {
+			a.log("Failed to update Claude Code: " + err.Error())
+		} else {
+			a.log("Claude Code updated successfully.")
+		}
+		return
+	}
+
+	a.log(fmt.Sprintf("Claude Code %s is available (installed: %s).", latest, current))
+	// Prompt once per release rather than on every start
+	if state := loadClaudeState(); state.Notified != latest {
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "claude-update-available", map[string]string{"installed": current, "latest": latest})
+		}
+		a.notify("Claude Code update available", fmt.Sprintf("Version %s is available. Installed: %s", latest, current))
+		state.Notified = latest
+		saveClaudeState(state)
+	}
+}
+
+// GetClaudeVersion returns the installed Claude Code version, or "" when it
+// is not installed.
+func (a *App) GetClaudeVersion() (string, error) {
+	npm := findNpm()
+	if npm == "" {
+		return "", fmt.Errorf("npm not found")
+	}
+	return claudeVersion(npm), nil
+}
+
+// ListClaudeVersions returns the versions published on the npm registry,
+// newest first.
+func (a *App) ListClaudeVersions() ([]string, error) {
+	versions, _, err := a.fetchClaudeVersions()
+	return versions, err
+}
+
+// InstallClaudeVersion installs a specific Claude Code version, or the
+// latest when version is empty.
+func (a *App) InstallClaudeVersion(version string) error {
+	npm := findNpm()
+	if npm == "" {
+		return fmt.Errorf("npm not found")
+	}
+	if err := a.installClaude(npm, strings.TrimSpace(version)); err != nil {
+		a.log("Failed to install Claude Code: " + err.Error())
+		return err
+	}
+	a.log("Claude Code " + claudeVersion(npm) + " installed.")
+	return nil
+}
+
+// RollbackClaude reinstalls the version replaced by the last install and
+// returns it.
+func (a *App) RollbackClaude() (string, error) {
+	previous := loadClaudeState().Previous
+	if previous == "" {
+		return "", fmt.Errorf("no previous Claude Code version recorded")
+	}
+	if err := a.InstallClaudeVersion(previous); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
@@ -1,5 +1,12 @@
 package main
 
+import (
+	"fmt"
+	"sync"
+
+	"github.com/energye/systray"
+)
+
 var trayTranslations = map[string]map[string]string{
 	"en": {
 		"title":   "Claude Config Manager",
@@ -8,6 +15,7 @@ var trayTranslations = map[string]map[string]string{
 		"quit":    "Quit Application",
 		"models":  "Models",
 		"actions": "Actions",
+		"local":   "Local models: %d/%d online",
 	},
 	"zh-Hans": {
 		"title":   "Claude 配置管理器",
@@ -16,6 +24,7 @@ var trayTranslations = map[string]map[string]string{
 		"quit":    "退出程序",
 		"models":  "模型选择",
 		"actions": "操作",
+		"local":   "本地模型：%d/%d 在线",
 	},
 	"zh-Hant": {
 		"title":   "Claude 配置管理器",
@@ -24,6 +33,7 @@ var trayTranslations = map[string]map[string]string{
 		"quit":    "退出程式",
 		"models":  "模型選擇",
 		"actions": "操作",
+		"local":   "本地模型：%d/%d 在線",
 	},
 	"ko": {
 		"title":   "Claude 구성 관리자",
@@ -32,6 +42,7 @@ var trayTranslations = map[string]map[string]string{
 		"quit":    "프로그램 종료",
 		"models":  "모델",
 		"actions": "작업",
+		"local":   "로컬 모델: %d/%d 온라인",
 	},
 	"ja": {
 		"title":   "Claude 設定マネージャー",
@@ -40,6 +51,7 @@ var trayTranslations = map[string]map[string]string{
 		"quit":    "終了",
 		"models":  "モデル",
 		"actions": "操作",
+		"local":   "ローカルモデル: %d/%d オンライン",
 	},
 	"de": {
 		"title":   "Claude Konfigurationsmanager",
@@ -48,6 +60,7 @@ var trayTranslations = map[string]map[string]string{
 		"quit":    "Beenden",
 		"models":  "Modelle",
 		"actions": "Aktionen",
+		"local":   "Lokale Modelle: %d/%d online",
 	},
 	"fr": {
 		"title":   "Gestionnaire de configuration Claude",
@@ -56,5 +69,115 @@ var trayTranslations = map[string]map[string]string{
 		"quit":    "Quitter",
 		"models":  "Modèles",
 		"actions": "Actions",
+		"local":   "Modèles locaux : %d/%d en ligne",
 	},
-}
\ No newline at end of file
+}
+
+// localTrayStatus keeps the tray's local model health item in step with
+// the latest probe and the UI language. The item stays hidden while no
+// local models are configured.
+type localTrayStatus struct {
+	mu     sync.Mutex
+	item   *systray.MenuItem
+	lang   string
+	online int
+	total  int
+}
+
+func (s *localTrayStatus) set(online, total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.online, s.total = online, total
+	s.refresh()
+}
+
+func (s *localTrayStatus) setLang(lang string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lang = lang
+	s.refresh()
+}
+
+// setItem points the status at a new menu item after the tray menu has
+// been rebuilt.
+func (s *localTrayStatus) setItem(item *systray.MenuItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.item = item
+	s.refresh()
+}
+
+func (s *localTrayStatus) refresh() {
+	if s.item == nil {
+		return
+	}
+	if s.total == 0 {
+		s.item.Hide()
+		return
+	}
+	t, ok := trayTranslations[s.lang]
+	if !ok {
+		t = trayTranslations["en"]
+	}
+	s.item.SetTitle(fmt.Sprintf(t["local"], s.online, s.total))
+	s.item.Show()
+}
+
+// trayModels tracks the model items in the tray menu, which the config and
+// key health listeners update from other goroutines.
+type trayModels struct {
+	mu     sync.Mutex
+	names  []string
+	items  map[string]*systray.MenuItem
+	health map[string]string
+}
+
+// set records the items of a freshly built menu and restores their badges.
+func (t *trayModels) set(names []string, items map[string]*systray.MenuItem) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.names, t.items = names, items
+	t.refresh()
+}
+
+// changed reports whether models no longer match the menu, so it has to be
+// rebuilt, e.g. after local model discovery added entries.
+func (t *trayModels) changed(models []ModelConfig) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(models) != len(t.names) {
+		return true
+	}
+	for i, m := range models {
+		if m.ModelName != t.names[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *trayModels) check(current string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for name, item := range t.items {
+		if name == current {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+}
+
+// setHealth shows the last key check as a coloured badge per model.
+func (t *trayModels) setHealth(status map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.health = status
+	t.refresh()
+}
+
+func (t *trayModels) refresh() {
+	for name, item := range t.items {
+		item.SetTitle(keyHealthBadge(t.health[name]) + name)
+	}
+}
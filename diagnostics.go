@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	wails_runtime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	SeverityOk      = "ok"
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// DiagnosticItem is one line of the environment checklist.
+type DiagnosticItem struct {
+	Id       string `json:"id"` // Stable key such as "node" or "env:ANTHROPIC_BASE_URL"
+	Title    string `json:"title"`
+	Value    string `json:"value"`
+	Severity string `json:"severity"`
+	Fix      string `json:"fix,omitempty"` // Suggested fix when the severity is warning or error
+	// FixCode names the fix so the frontend can show it in the user's
+	// language, filling in FixParams; Fix is the English text
+	FixCode   string            `json:"fix_code,omitempty"`
+	FixParams map[string]string `json:"fix_params,omitempty"`
+}
+
+// DiagnosticReport describes the environment Claude Code runs in.
+type DiagnosticReport struct {
+	GeneratedAt string           `json:"generated_at"`
+	Os          string           `json:"os"`
+	Arch        string           `json:"arch"`
+	Items       []DiagnosticItem `json:"items"`
+}
+
+func (r *DiagnosticReport) add(item DiagnosticItem) {
+	r.Items = append(r.Items, item)
+}
+
+// commandOutput runs a command and returns its trimmed standard output.
+func commandOutput(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	hideCommandWindow(cmd)
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// dirWritable reports whether files can be created in dir.
+func dirWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".cceasy-check-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// npmGlobalDir is where `npm install -g` puts packages for a prefix.
+func npmGlobalDir(prefix string) string {
+	if runtime.GOOS == "windows" {
+		return prefix
+	}
+	return filepath.Join(prefix, "lib", "node_modules")
+}
+
+// DiagnoseEnvironment inspects the tools, settings and files Claude Code
+// depends on. Every item carries a severity and, for problems, a fix.
+func (a *App) DiagnoseEnvironment() DiagnosticReport {
+	report := DiagnosticReport{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Os:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Items:       []DiagnosticItem{},
+	}
+	config, _ := a.LoadConfig()
+
+	diagnoseNode(&report, config)
+	npm := diagnoseNpm(&report)
+	diagnoseClaude(&report, config, npm)
+	diagnoseGit(&report)
+	diagnoseTerminals(&report)
+
+	report.add(DiagnosticItem{
+		Id:       "path",
+		Title:    "PATH",
+		Value:    os.Getenv("PATH"),
+		Severity: SeverityInfo,
+	})
+
+	diagnoseAnthropicEnv(&report)
+	diagnoseFiles(&report)
+	return report
+}
+
+func diagnoseNode(r *DiagnosticReport, config AppConfig) {
+	item := DiagnosticItem{Id: "node", Title: "Node.js"}
+	path, err := exec.LookPath("node")
+	if err != nil {
+		item.Severity = SeverityError
+		item.Value = "Not found"
+		item.Fix = "Run the environment check to install Node.js."
+		item.FixCode = "install_node"
+		r.add(item)
+		return
+	}
+	version, _ := nodeVersion(path)
+	item.Value = strings.TrimSpace(version + " " + path)
+	minimum := config.Node.minimumVersion()
+	if nodeVersionAtLeast(version, minimum) {
+		item.Severity = SeverityOk
+	} else {
+		item.Severity = SeverityError
+		item.Fix = fmt.Sprintf("Node.js %s or newer is required. Install a private Node.js under %s from the environment check.", minimum, getPrivateNodeDir())
+		item.FixCode = "node_too_old"
+		item.FixParams = map[string]string{"minimum": minimum, "dir": getPrivateNodeDir()}
+	}
+	r.add(item)
+}
+
+// diagnoseNpm reports npm and its global prefix, and returns the npm path.
+func diagnoseNpm(r *DiagnosticReport) string {
+	npm := findNpm()
+	if npm == "" {
+		r.add(DiagnosticItem{
+			Id:       "npm",
+			Title:    "npm",
+			Value:    "Not found",
+			Severity: SeverityError,
+			Fix:      "npm ships with Node.js. Reinstall Node.js from the environment check.",
+			FixCode:  "reinstall_node",
+		})
+		return ""
+	}
+	version, _ := commandOutput(npm, "--version")
+	r.add(DiagnosticItem{
+		Id:       "npm",
+		Title:    "npm",
+		Value:    strings.TrimSpace(version + " " + npm),
+		Severity: SeverityOk,
+	})
+
+	prefix, err := commandOutput(npm, "config", "get", "prefix")
+	item := DiagnosticItem{Id: "npm_prefix", Title: "npm global prefix", Value: prefix}
+	switch {
+	case err != nil || prefix == "":
+		item.Severity = SeverityWarning
+		item.Value = "Unknown"
+		item.Fix = "Check that `npm config get prefix` works in a terminal."
+		item.FixCode = "check_npm_prefix"
+	case !globalPrefixWritable(npm):
+		item.Severity = SeverityInfo
+		item.Value = prefix + " (not writable, Claude Code is installed into " + getUserNpmPrefix() + ")"
+	default:
+		item.Severity = SeverityOk
+	}
+	r.add(item)
+	return npm
+}
+
+func diagnoseClaude(r *DiagnosticReport, config AppConfig, npm string) {
+	item := DiagnosticItem{Id: "claude", Title: "Claude Code"}
+	path, _ := exec.LookPath("claude")
+	version := ""
+	if npm != "" {
+		version = claudeVersion(npm)
+	}
+	item.Value = strings.TrimSpace(version + " " + path)
+
+	cc := config.ClaudeCode
+	switch {
+	case path == "" && version == "":
+		item.Severity = SeverityError
+		item.Value = "Not found"
+		item.Fix = "Run the environment check to install Claude Code."
+		item.FixCode = "install_claude"
+	case path == "":
+		item.Severity = SeverityWarning
+		item.Fix = "Claude Code is installed but its directory is not on PATH. Add the npm global bin directory to PATH."
+		item.FixCode = "claude_not_on_path"
+	case cc.policy() == ClaudePolicyPinned && cc.Version != "" && version != "" && version != cc.Version:
+		item.Severity = SeverityWarning
+		item.Fix = fmt.Sprintf("Version %s is pinned. Run the environment check to install it.", cc.Version)
+		item.FixCode = "install_pinned_claude"
+		item.FixParams = map[string]string{"version": cc.Version}
+	default:
+		item.Severity = SeverityOk
+	}
+	r.add(item)
+}
+
+func diagnoseGit(r *DiagnosticReport) {
+	item := DiagnosticItem{Id: "git", Title: "Git"}
+	path, err := exec.LookPath("git")
+	if err != nil {
+		item.Value = "Not found"
+		// Claude Code runs its shell commands through Git Bash on Windows
+		if runtime.GOOS == "windows" {
+			item.Severity = SeverityError
+			item.Fix = "Claude Code needs Git for Windows. Run the environment check to install it."
+			item.FixCode = "install_git_windows"
+		} else {
+			item.Severity = SeverityWarning
+			item.Fix = "Install git with your package manager so Claude Code can work with repositories."
+			item.FixCode = "install_git"
+		}
+		r.add(item)
+		return
+	}
+	version, _ := commandOutput(path, "--version")
+	item.Value = strings.TrimSpace(strings.TrimPrefix(version, "git version ") + " " + path)
+	item.Severity = SeverityOk
+	r.add(item)
+}
+
+func diagnoseTerminals(r *DiagnosticReport) {
+	terminals := detectTerminals()
+	item := DiagnosticItem{Id: "terminals", Title: "Terminals", Value: strings.Join(terminals, ", ")}
+	if len(terminals) == 0 {
+		item.Severity = SeverityError
+		item.Value = "None found"
+		item.Fix = "Claude Code is launched in a terminal window. Install a terminal emulator."
+		item.FixCode = "install_terminal"
+	} else {
+		item.Severity = SeverityOk
+	}
+	r.add(item)
+}
+
+// diagnoseAnthropicEnv flags ANTHROPIC_* variables in the environment that
+// disagree with what cceasy wrote to ~/.claude/settings.json, since they
+// can silently redirect Claude Code to another endpoint or key.
+func diagnoseAnthropicEnv(r *DiagnosticReport) {
+	expected := map[string]string{}
+	if home, err := os.UserHomeDir(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".claude", "settings.json")); err == nil {
+			var settings struct {
+				Env map[string]string `json:"env"`
+			}
+			if json.Unmarshal(data, &settings) == nil && settings.Env != nil {
+				expected = settings.Env
+			}
+		}
+	}
+
+	names := []string{}
+	for _, kv := range os.Environ() {
+		if name, _, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(name, "ANTHROPIC_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := os.Getenv(name)
+		shown := value
+		if strings.Contains(name, "KEY") || strings.Contains(name, "TOKEN") {
+			shown = maskKey(value)
+		}
+		item := DiagnosticItem{Id: "env:" + name, Title: name, Value: shown, Severity: SeverityOk}
+		if want, ok := expected[name]; !ok || want != value {
+			item.Severity = SeverityWarning
+			item.Fix = fmt.Sprintf("%s is set in the environment and may override the selected model. Remove it from your shell profile or system environment.", name)
+			item.FixCode = "anthropic_env_override"
+			item.FixParams = map[string]string{"name": name}
+		}
+		r.add(item)
+	}
+}
+
+// diagnoseFiles checks that the files cceasy manages can be written and
+// are not writable by other users.
+func diagnoseFiles(r *DiagnosticReport) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	files := []struct {
+		id, path string
+	}{
+		{"config", filepath.Join(home, ".claude_model_config.json")},
+		{"settings", filepath.Join(home, ".claude", "settings.json")},
+		{"claude_json", filepath.Join(home, ".claude.json")},
+		{"state_dir", filepath.Join(home, ".cceasy")},
+	}
+	for _, f := range files {
+		item := DiagnosticItem{Id: "file:" + f.id, Title: f.path}
+		info, err := os.Stat(f.path)
+		if os.IsNotExist(err) {
+			item.Severity = SeverityInfo
+			item.Value = "Not created yet"
+			r.add(item)
+			continue
+		}
+		if err != nil {
+			item.Severity = SeverityError
+			item.Value = err.Error()
+			item.Fix = "Check the permissions of the parent directory."
+			item.FixCode = "check_parent_dir"
+			r.add(item)
+			continue
+		}
+
+		item.Value = info.Mode().Perm().String()
+		writable := false
+		if info.IsDir() {
+			writable = dirWritable(f.path)
+		} else if file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0); err == nil {
+			file.Close()
+			writable = true
+		}
+
+		switch {
+		case !writable && runtime.GOOS == "windows":
+			item.Severity = SeverityError
+			item.Fix = "cceasy cannot write here. Give your user write access in the file's Properties > Security tab."
+			item.FixCode = "file_not_writable_windows"
+		case !writable:
+			item.Severity = SeverityError
+			item.Fix = "cceasy cannot write here. Give your user ownership, e.g. `chown $USER " + f.path + "`."
+			item.FixCode = "file_not_writable"
+			item.FixParams = map[string]string{"path": f.path}
+		case runtime.GOOS != "windows" && info.Mode().Perm()&0022 != 0:
+			item.Severity = SeverityWarning
+			item.Fix = "Other users can modify this file. Run `chmod go-w " + f.path + "`."
+			item.FixCode = "file_group_writable"
+			item.FixParams = map[string]string{"path": f.path}
+		default:
+			item.Severity = SeverityOk
+		}
+		r.add(item)
+	}
+}
+
+// ExportDiagnostics saves a diagnostics report as JSON to a file chosen by
+// the user and returns its path, or "" when cancelled.
+func (a *App) ExportDiagnostics() (string, error) {
+	path, err := wails_runtime.SaveFileDialog(a.ctx, wails_runtime.SaveDialogOptions{
+		Title:           "Export Diagnostics",
+		DefaultFilename: "cceasy-diagnostics.json",
+		Filters:         []wails_runtime.FileFilter{{DisplayName: "JSON", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	data, err := json.MarshalIndent(a.DiagnoseEnvironment(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
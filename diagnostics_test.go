@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiagnosticFixCodes(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ".claude"), 0755)
+	os.WriteFile(filepath.Join(home, ".claude", "settings.json"), []byte(`{"env":{"ANTHROPIC_MODEL":"m"}}`), 0644)
+	claudeJson := filepath.Join(home, ".claude.json")
+	os.WriteFile(claudeJson, []byte(`{}`), 0644)
+	os.Chmod(claudeJson, 0666)
+	t.Setenv("ANTHROPIC_BASE_URL", "https://elsewhere.example")
+	t.Setenv("ANTHROPIC_MODEL", "m")
+
+	var r DiagnosticReport
+	diagnoseAnthropicEnv(&r)
+	diagnoseFiles(&r)
+	byId := map[string]DiagnosticItem{}
+	for _, item := range r.Items {
+		byId[item.Id] = item
+		if item.Fix != "" && item.FixCode == "" {
+			t.Errorf("%s has a fix without a code", item.Id)
+		}
+	}
+
+	env := byId["env:ANTHROPIC_BASE_URL"]
+	if env.FixCode != "anthropic_env_override" || env.FixParams["name"] != "ANTHROPIC_BASE_URL" {
+		t.Errorf("env item = %+v", env)
+	}
+	if model := byId["env:ANTHROPIC_MODEL"]; model.FixCode != "" {
+		t.Errorf("matching variable has fix %q", model.FixCode)
+	}
+	file := byId["file:claude_json"]
+	if file.FixCode != "file_group_writable" || file.FixParams["path"] != claudeJson {
+		t.Errorf("file item = %+v", file)
+	}
+	if missing := byId["file:config"]; missing.Severity != SeverityInfo || missing.FixCode != "" {
+		t.Errorf("missing config item = %+v", missing)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	DownloadStarted  = "started"
+	DownloadProgress = "progress"
+	DownloadRetrying = "retrying"
+	DownloadDone     = "done"
+	DownloadFailed   = "failed"
+)
+
+// Some mirrors and CDNs answer 403 to unknown user agents
+const downloadUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// DownloadEvent reports the state of a download to the frontend.
+type DownloadEvent struct {
+	File       string  `json:"file"`
+	Url        string  `json:"url"`
+	Status     string  `json:"status"`
+	Downloaded int64   `json:"downloaded"`
+	Total      int64   `json:"total"` // -1 when the server did not say
+	Percent    float64 `json:"percent"`
+	Attempt    int     `json:"attempt"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// downloader fetches a file from the first mirror that serves it. Partial
+// data is kept in <dest>.part and resumed with a Range request, so retries
+// and later mirrors continue where the last attempt stopped.
+type downloader struct {
+	client       *http.Client  // Must not set a Timeout, which would cap the whole transfer
+	retries      int           // Attempts per mirror
+	backoff      time.Duration // Wait before the first retry, doubled after each one
+	maxBackoff   time.Duration
+	stallTimeout time.Duration // Abort an attempt when no data arrives for this long
+	userAgent    string
+	onEvent      func(DownloadEvent)
+}
+
+func newDownloader(client *http.Client, onEvent func(DownloadEvent)) *downloader {
+	return &downloader{
+		client:       client,
+		retries:      4,
+		backoff:      time.Second,
+		maxBackoff:   30 * time.Second,
+		stallTimeout: 60 * time.Second,
+		userAgent:    downloadUserAgent,
+		onEvent:      onEvent,
+	}
+}
+
+// errPermanent marks a response that retrying the same URL cannot fix.
+type errPermanent struct{ err error }
+
+func (e errPermanent) Error() string { return e.err.Error() }
+
+func (d *downloader) emit(e DownloadEvent) {
+	if d.onEvent != nil {
+		d.onEvent(e)
+	}
+}
+
+// download saves the file to dest, trying urls in order, and returns the
+// URL it was finally fetched from.
+func (d *downloader) download(ctx context.Context, dest string, urls []string) (string, error) {
+	if len(urls) == 0 {
+		return "", fmt.Errorf("no download URL")
+	}
+	file := filepath.Base(dest)
+	part := dest + ".part"
+
+	var lastErr error
+	for _, url := range urls {
+		delay := d.backoff
+		for attempt := 1; attempt <= d.retries; attempt++ {
+			if attempt == 1 {
+				d.emit(DownloadEvent{File: file, Url: url, Status: DownloadStarted, Total: -1, Attempt: attempt})
+			}
+			err := d.attempt(ctx, part, DownloadEvent{File: file, Url: url, Attempt: attempt})
+			if err == nil {
+				if err := os.Rename(part, dest); err != nil {
+					return "", err
+				}
+				d.emit(DownloadEvent{File: file, Url: url, Status: DownloadDone, Attempt: attempt, Percent: 100})
+				return url, nil
+			}
+			if ctx.Err() != nil {
+				d.emit(DownloadEvent{File: file, Url: url, Status: DownloadFailed, Attempt: attempt, Error: ctx.Err().Error()})
+				return "", ctx.Err()
+			}
+			lastErr = err
+			var permanent errPermanent
+			if errors.As(err, &permanent) || attempt == d.retries {
+				break
+			}
+
+			d.emit(DownloadEvent{File: file, Url: url, Status: DownloadRetrying, Attempt: attempt, Error: err.Error()})
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > d.maxBackoff {
+				delay = d.maxBackoff
+			}
+		}
+	}
+	d.emit(DownloadEvent{File: file, Status: DownloadFailed, Error: lastErr.Error()})
+	return "", fmt.Errorf("download of %s failed from all mirrors: %w", file, lastErr)
+}
+
+// attempt makes one request, appending to the partial file when the
+// server honours the Range header and starting over when it does not.
+func (d *downloader) attempt(ctx context.Context, part string, e DownloadEvent) error {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stall := time.AfterFunc(d.stallTimeout, cancel)
+	defer stall.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.Url, nil)
+	if err != nil {
+		return errPermanent{err}
+	}
+	req.Header.Set("User-Agent", d.userAgent)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := int64(-1)
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+		total = resp.ContentLength
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is only complete when the server confirms its size;
+		// otherwise it is stale or corrupt and the next attempt starts over
+		if size, ok := unsatisfiedRangeSize(resp.Header.Get("Content-Range")); ok && size == offset {
+			return nil
+		}
+		if err := os.Remove(part); err != nil {
+			return errPermanent{err}
+		}
+		return fmt.Errorf("partial download of %d bytes does not match the server, restarting", offset)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("download failed with status: %s", resp.Status)
+	default:
+		return errPermanent{fmt.Errorf("download failed with status: %s", resp.Status)}
+	}
+
+	out, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return errPermanent{err}
+	}
+	defer out.Close()
+
+	downloaded := offset
+	buffer := make([]byte, 32768)
+	for {
+		n, err := resp.Body.Read(buffer)
+		if n > 0 {
+			stall.Reset(d.stallTimeout)
+			if _, werr := out.Write(buffer[:n]); werr != nil {
+				return errPermanent{werr}
+			}
+			downloaded += int64(n)
+			e.Status, e.Downloaded, e.Total = DownloadProgress, downloaded, total
+			if total > 0 {
+				e.Percent = float64(downloaded) / float64(total) * 100
+			}
+			d.emit(e)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("interrupted download: %v", err)
+		}
+	}
+	if total >= 0 && downloaded < total {
+		return fmt.Errorf("interrupted download: %d of %d bytes", downloaded, total)
+	}
+	return nil
+}
+
+// unsatisfiedRangeSize reads the full size from the "bytes */<size>"
+// Content-Range header of a 416 response.
+func unsatisfiedRangeSize(header string) (int64, bool) {
+	rest, ok := strings.CutPrefix(header, "bytes */")
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(rest), 10, 64)
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+var (
+	downloadMu     sync.Mutex
+	cancelDownload context.CancelFunc
+)
+
+// download fetches a file through the shared downloader, reporting
+// progress as "download-progress" events and in the log. It can be
+// aborted with CancelDownload.
+func (a *App) download(dest string, urls []string) (string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	downloadMu.Lock()
+	cancelDownload = cancel
+	downloadMu.Unlock()
+	defer func() {
+		downloadMu.Lock()
+		cancelDownload = nil
+		downloadMu.Unlock()
+		cancel()
+	}()
+
+	var lastReport time.Time
+	d := newDownloader(a.httpClient(0), func(e DownloadEvent) {
+		// Progress is reported twice a second rather than per chunk
+		if e.Status == DownloadProgress {
+			if time.Since(lastReport) < 500*time.Millisecond {
+				return
+			}
+			lastReport = time.Now()
+		}
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "download-progress", e)
+		}
+		switch e.Status {
+		case DownloadStarted:
+			a.log(fmt.Sprintf("Requesting URL: %s", e.Url))
+		case DownloadRetrying:
+			a.log(fmt.Sprintf("Download attempt %d from %s failed: %s. Retrying...", e.Attempt, e.Url, e.Error))
+		case DownloadProgress:
+			if e.Total > 0 {
+				a.log(fmt.Sprintf("Downloading %s (%.1f%%): %d/%d bytes", e.File, e.Percent, e.Downloaded, e.Total))
+			}
+		}
+	})
+	return d.download(ctx, dest, urls)
+}
+
+// CancelDownload aborts the download in progress, if any.
+func (a *App) CancelDownload() {
+	downloadMu.Lock()
+	defer downloadMu.Unlock()
+	if cancelDownload != nil {
+		cancelDownload()
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyFileServer serves payload with Range support and drops the
+// connection partway through the first drops responses.
+type flakyFileServer struct {
+	payload []byte
+	drops   int
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (s *flakyFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	drop := s.drops > 0
+	if drop {
+		s.drops--
+	}
+	s.mu.Unlock()
+
+	start := 0
+	if rg := r.Header.Get("Range"); rg != "" {
+		fmt.Sscanf(rg, "bytes=%d-", &start)
+		if start >= len(s.payload) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(s.payload)))
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(s.payload)-1, len(s.payload)))
+		w.Header().Set("Content-Length", fmt.Sprint(len(s.payload)-start))
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.Header().Set("Content-Length", fmt.Sprint(len(s.payload)))
+		w.WriteHeader(http.StatusOK)
+	}
+	body := s.payload[start:]
+	if drop {
+		w.Write(body[:len(body)/2])
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	w.Write(body)
+}
+
+func (s *flakyFileServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+func testDownloader(client *http.Client) *downloader {
+	d := newDownloader(client, nil)
+	d.backoff = time.Millisecond
+	d.maxBackoff = time.Millisecond
+	return d
+}
+
+func testPayload() []byte {
+	return []byte(strings.Repeat("cceasy download ", 4096))
+}
+
+func TestDownloadResumesFromPartialFile(t *testing.T) {
+	payload := testPayload()
+	files := &flakyFileServer{payload: payload}
+	srv := httptest.NewServer(files)
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "node.zip")
+	if err := os.WriteFile(dest+".part", payload[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDownloader(srv.Client()).download(context.Background(), dest, []string{srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(dest)
+	if string(got) != string(payload) {
+		t.Fatalf("got %d bytes, want %d", len(got), len(payload))
+	}
+	if ranges := files.requests(); len(ranges) != 1 || ranges[0] != "bytes=1000-" {
+		t.Fatalf("ranges = %q", ranges)
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Fatal("partial file left behind")
+	}
+}
+
+func TestDownloadResumesAfterDroppedConnections(t *testing.T) {
+	payload := testPayload()
+	files := &flakyFileServer{payload: payload, drops: 2}
+	srv := httptest.NewServer(files)
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "node.zip")
+	var retries int
+	d := testDownloader(srv.Client())
+	d.onEvent = func(e DownloadEvent) {
+		if e.Status == DownloadRetrying {
+			retries++
+		}
+	}
+	if _, err := d.download(context.Background(), dest, []string{srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(dest)
+	if string(got) != string(payload) {
+		t.Fatalf("got %d bytes, want %d", len(got), len(payload))
+	}
+	ranges := files.requests()
+	if len(ranges) != 3 || ranges[0] != "" || ranges[1] == "" || ranges[2] == "" {
+		t.Fatalf("ranges = %q", ranges)
+	}
+	if retries != 2 {
+		t.Fatalf("retries = %d", retries)
+	}
+}
+
+func TestDownloadRangeNotSatisfiable(t *testing.T) {
+	payload := testPayload()
+	srv := httptest.NewServer(&flakyFileServer{payload: payload})
+	defer srv.Close()
+
+	t.Run("complete", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "node.zip")
+		os.WriteFile(dest+".part", payload, 0644)
+		if _, err := testDownloader(srv.Client()).download(context.Background(), dest, []string{srv.URL}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(dest); string(got) != string(payload) {
+			t.Fatalf("got %d bytes", len(got))
+		}
+	})
+
+	t.Run("oversized partial restarts", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "node.zip")
+		os.WriteFile(dest+".part", append(append([]byte(nil), payload...), "garbage"...), 0644)
+		if _, err := testDownloader(srv.Client()).download(context.Background(), dest, []string{srv.URL}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(dest); string(got) != string(payload) {
+			t.Fatalf("got %d bytes, want %d", len(got), len(payload))
+		}
+	})
+
+	t.Run("size unknown restarts", func(t *testing.T) {
+		var calls int
+		blind := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.Header.Get("Range") != "" {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.Write(payload)
+		}))
+		defer blind.Close()
+
+		dest := filepath.Join(t.TempDir(), "node.zip")
+		os.WriteFile(dest+".part", payload[:10], 0644)
+		if _, err := testDownloader(blind.Client()).download(context.Background(), dest, []string{blind.URL}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(dest); string(got) != string(payload) {
+			t.Fatalf("got %d bytes, want %d", len(got), len(payload))
+		}
+		if calls != 2 {
+			t.Fatalf("calls = %d", calls)
+		}
+	})
+}
+
+func TestDownloadFallsBackToNextMirror(t *testing.T) {
+	var brokenCalls int
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		brokenCalls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	payload := testPayload()
+	good := httptest.NewServer(&flakyFileServer{payload: payload})
+	defer good.Close()
+
+	dest := filepath.Join(t.TempDir(), "node.zip")
+	d := testDownloader(http.DefaultClient)
+	d.retries = 2
+	url, err := d.download(context.Background(), dest, []string{broken.URL, missing.URL, good.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != good.URL {
+		t.Fatalf("downloaded from %s", url)
+	}
+	if brokenCalls != 2 {
+		t.Fatalf("5xx mirror tried %d times, want 2", brokenCalls)
+	}
+
+	if _, err := d.download(context.Background(), dest, []string{missing.URL}); err == nil {
+		t.Fatal("expected failure when no mirror serves the file")
+	}
+}
+
+func TestDownloadStallTimeout(t *testing.T) {
+	payload := testPayload()
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			w.Header().Set("Content-Length", fmt.Sprint(len(payload)))
+			w.Write(payload[:100])
+			w.(http.Flusher).Flush()
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		var start int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+		w.Header().Set("Content-Length", fmt.Sprint(len(payload)-start))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(payload[start:])
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	dest := filepath.Join(t.TempDir(), "node.zip")
+	d := testDownloader(srv.Client())
+	d.stallTimeout = 100 * time.Millisecond
+	if _, err := d.download(context.Background(), dest, []string{srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != string(payload) {
+		t.Fatalf("got %d bytes, want %d", len(got), len(payload))
+	}
+}
+
+func TestDownloadCancel(t *testing.T) {
+	started := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1000000")
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	dest := filepath.Join(t.TempDir(), "node.zip")
+	var failed bool
+	d := testDownloader(srv.Client())
+	d.onEvent = func(e DownloadEvent) {
+		if e.Status == DownloadFailed {
+			failed = true
+		}
+	}
+	if _, err := d.download(ctx, dest, []string{srv.URL}); err != context.Canceled {
+		t.Fatalf("err = %v", err)
+	}
+	if !failed {
+		t.Fatal("no failed event")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatal("cancelled download produced a file")
+	}
+}
@@ -2,9 +2,10 @@ import {useEffect, useState, useRef} from 'react';
 import './App.css';
 import {buildNumber} from './version';
 import appIcon from './assets/images/appicon.png';
-import {LoadConfig, SaveConfig, CheckEnvironment, ResizeWindow, LaunchClaude, SelectProjectDir, SetLanguage, GetUserHomeDir, CheckUpdate, RecoverCC, ShowMessage} from "../wailsjs/go/main/App";
+import {LoadConfig, SaveConfig, CheckEnvironment, ResizeWindow, LaunchClaude, SelectProjectDir, SetLanguage, GetUserHomeDir, CheckUpdate, RecoverCC, ShowMessage, GetQueueStatus, GetBalances, InstallPrivateNode, InstallClaudeVersion, MigrateClaudeToUserPrefix} from "../wailsjs/go/main/App";
 import {WindowHide, EventsOn, EventsOff, BrowserOpenURL, ClipboardGetText, Quit} from "../wailsjs/runtime";
 import {main} from "../wailsjs/go/models";
+import {ToolsModal} from "./Tools";
 
 const subscriptionUrls: {[key: string]: string} = {
     "glm": "https://bigmodel.cn/glm-coding",
@@ -15,6 +16,12 @@ const subscriptionUrls: {[key: string]: string} = {
 
 const APP_VERSION = "1.3.2.1";
 
+// Payload of the "node-outdated" event
+type NodeOutdated = {path: string, version: string, minimum: string};
+
+// Payload of the "claude-update-available" event
+type ClaudeUpdate = {installed: string, latest: string};
+
 const translations: any = {
     "en": {
         "title": "Claude Code Easy Suite",
@@ -65,7 +72,79 @@ const translations: any = {
         "foundNewVersion": "Found new version",
         "downloadNow": "Download Now",
         "paste": "Paste",
-        "bugReport": "Bug Report or Suggestion"
+        "bugReport": "Bug Report or Suggestion",
+        "tools": "Tools",
+        "captures": "Captures",
+        "noCaptures": "No captures yet. Turn on gateway capture mode to record requests.",
+        "back": "Back",
+        "markForDiff": "Mark",
+        "unmark": "Unmark",
+        "compare": "Diff",
+        "captureHint": "Click an exchange to view it. Mark one exchange, then click Diff on another to compare them.",
+        "apiKeys": "API Keys",
+        "requests": "Requests",
+        "failures": "Failures",
+        "lastUsed": "Last Used",
+        "keyState": "State",
+        "keyReady": "Ready",
+        "benchedUntil": "Benched until",
+        "noKeys": "No API keys configured.",
+        "queue": "Queue",
+        "inFlight": "In flight",
+        "queued": "Queued",
+        "noQueue": "No model has a concurrency limit.",
+        "keyCheck": "Last check",
+        "checkKeys": "Check keys now",
+        "checking": "Checking...",
+        "keyHealth_ok": "Healthy",
+        "keyHealth_invalid": "Invalid key",
+        "keyHealth_no_credit": "No credit",
+        "keyHealth_rate_limited": "Rate limited",
+        "keyHealth_error": "Check failed",
+        "keyHealth_unchecked": "Not checked",
+        "balance": "Balance",
+        "nodeOutdatedTitle": "Node.js is too old",
+        "nodeOutdatedMessage": "Claude Code needs Node.js {minimum} or later, but this system has {version}:",
+        "nodePrivateHint": "A private copy of Node.js can be installed for Claude Code under ~/.cceasy/node. The system Node.js is left untouched.",
+        "installPrivateNode": "Install private Node.js",
+        "installing": "Installing...",
+        "nodeInstalled": "Node.js installed. Claude Code setup continues in the background.",
+        "later": "Later",
+        "claudeUpdateTitle": "Claude Code update available",
+        "claudeUpdateMessage": "Claude Code {latest} is available. Installed: {installed}.",
+        "claudeUpdateHint": "Set \"policy\" in claude_code to \"auto\" to install new releases automatically, or \"pinned\" to stay on one version.",
+        "updateNow": "Update now",
+        "claudeUpdated": "Claude Code {latest} installed.",
+        "installedVersion": "Installed version",
+        "notInstalled": "not installed",
+        "listVersions": "List versions",
+        "install": "Install",
+        "rollback": "Roll back",
+        "done": "Done",
+        "claudeVersionHint": "Roll back reinstalls the version replaced by the last install.",
+        "diagnostics": "Diagnostics",
+        "recheck": "Check again",
+        "exportDiagnostics": "Export",
+        "exportedTo": "Saved to",
+        "fix_install_node": "Run the environment check to install Node.js.",
+        "fix_node_too_old": "Node.js {minimum} or newer is required. Install a private Node.js under {dir} from the environment check.",
+        "fix_reinstall_node": "npm ships with Node.js. Reinstall Node.js from the environment check.",
+        "fix_check_npm_prefix": "Check that `npm config get prefix` works in a terminal.",
+        "fix_install_claude": "Run the environment check to install Claude Code.",
+        "fix_claude_not_on_path": "Claude Code is installed but its directory is not on PATH. Add the npm global bin directory to PATH.",
+        "fix_install_pinned_claude": "Version {version} is pinned. Run the environment check to install it.",
+        "fix_install_git_windows": "Claude Code needs Git for Windows. Run the environment check to install it.",
+        "fix_install_git": "Install git with your package manager so Claude Code can work with repositories.",
+        "fix_install_terminal": "Claude Code is launched in a terminal window. Install a terminal emulator.",
+        "fix_anthropic_env_override": "{name} is set in the environment and may override the selected model. Remove it from your shell profile or system environment.",
+        "fix_check_parent_dir": "Check the permissions of the parent directory.",
+        "fix_file_not_writable_windows": "cceasy cannot write here. Give your user write access in the file's Properties > Security tab.",
+        "fix_file_not_writable": "cceasy cannot write here. Give your user ownership, e.g. `chown $USER {path}`.",
+        "fix_file_group_writable": "Other users can modify this file. Run `chmod go-w {path}`.",
+        "migrationTitle": "Move Claude Code to your user folder",
+        "migrationMessage": "Claude Code is installed in the global npm prefix, which needs administrator rights to update. It can be reinstalled, at the same version, into:",
+        "migrate": "Move",
+        "migrationDone": "Claude Code was moved. Updates no longer need administrator rights."
     },
     "zh-Hans": {
         "title": "Claude Code Easy Suite",
@@ -116,7 +195,79 @@ const translations: any = {
         "foundNewVersion": "发现新版本",
         "downloadNow": "立即下载",
         "paste": "粘贴",
-        "bugReport": "Bug 报告或建议"
+        "bugReport": "Bug 报告或建议",
+        "tools": "工具",
+        "captures": "抓包记录",
+        "noCaptures": "暂无记录。开启网关抓包模式后会记录请求。",
+        "back": "返回",
+        "markForDiff": "标记",
+        "unmark": "取消标记",
+        "compare": "对比",
+        "captureHint": "点击一条记录查看详情。先标记一条记录，再点击另一条的“对比”查看差异。",
+        "apiKeys": "API 密钥",
+        "requests": "请求数",
+        "failures": "失败数",
+        "lastUsed": "最近使用",
+        "keyState": "状态",
+        "keyReady": "可用",
+        "benchedUntil": "暂停至",
+        "noKeys": "尚未配置 API 密钥。",
+        "queue": "请求队列",
+        "inFlight": "进行中",
+        "queued": "排队中",
+        "noQueue": "没有模型设置并发上限。",
+        "keyCheck": "最近检查",
+        "checkKeys": "立即检查 Key",
+        "checking": "检查中...",
+        "keyHealth_ok": "正常",
+        "keyHealth_invalid": "Key 无效",
+        "keyHealth_no_credit": "余额不足",
+        "keyHealth_rate_limited": "被限流",
+        "keyHealth_error": "检查失败",
+        "keyHealth_unchecked": "未检查",
+        "balance": "余额",
+        "nodeOutdatedTitle": "Node.js 版本过低",
+        "nodeOutdatedMessage": "Claude Code 需要 Node.js {minimum} 或更高版本，但系统中的版本为 {version}：",
+        "nodePrivateHint": "可以在 ~/.cceasy/node 下为 Claude Code 单独安装一份 Node.js，系统中的 Node.js 不会被改动。",
+        "installPrivateNode": "安装私有 Node.js",
+        "installing": "安装中...",
+        "nodeInstalled": "Node.js 已安装，Claude Code 将在后台继续安装。",
+        "later": "稍后",
+        "claudeUpdateTitle": "Claude Code 有新版本",
+        "claudeUpdateMessage": "Claude Code {latest} 已发布，当前安装的版本为 {installed}。",
+        "claudeUpdateHint": "将 claude_code 中的 \"policy\" 设为 \"auto\" 可自动安装新版本，设为 \"pinned\" 可固定在一个版本。",
+        "updateNow": "立即更新",
+        "claudeUpdated": "Claude Code {latest} 已安装。",
+        "installedVersion": "已安装版本",
+        "notInstalled": "未安装",
+        "listVersions": "列出版本",
+        "install": "安装",
+        "rollback": "回滚",
+        "done": "完成",
+        "claudeVersionHint": "回滚会重新安装上一次安装前的版本。",
+        "diagnostics": "环境诊断",
+        "recheck": "重新检测",
+        "exportDiagnostics": "导出",
+        "exportedTo": "已保存到",
+        "fix_install_node": "运行环境检测以安装 Node.js。",
+        "fix_node_too_old": "需要 Node.js {minimum} 或更高版本。可在环境检测中将私有 Node.js 安装到 {dir}。",
+        "fix_reinstall_node": "npm 随 Node.js 一起安装。请在环境检测中重新安装 Node.js。",
+        "fix_check_npm_prefix": "请确认在终端中可以运行 `npm config get prefix`。",
+        "fix_install_claude": "运行环境检测以安装 Claude Code。",
+        "fix_claude_not_on_path": "Claude Code 已安装，但其目录不在 PATH 中。请将 npm 全局 bin 目录加入 PATH。",
+        "fix_install_pinned_claude": "已固定版本 {version}。运行环境检测以安装该版本。",
+        "fix_install_git_windows": "Claude Code 需要 Git for Windows。运行环境检测以安装。",
+        "fix_install_git": "请用系统包管理器安装 git，以便 Claude Code 操作代码仓库。",
+        "fix_install_terminal": "Claude Code 在终端窗口中启动。请安装一个终端模拟器。",
+        "fix_anthropic_env_override": "环境变量中设置了 {name}，可能会覆盖所选模型。请从 shell 配置文件或系统环境变量中删除。",
+        "fix_check_parent_dir": "请检查上级目录的权限。",
+        "fix_file_not_writable_windows": "cceasy 无法写入此处。请在文件“属性 > 安全”中为当前用户授予写入权限。",
+        "fix_file_not_writable": "cceasy 无法写入此处。请将所有者改为当前用户，例如 `chown $USER {path}`。",
+        "fix_file_group_writable": "其他用户可以修改此文件。请运行 `chmod go-w {path}`。",
+        "migrationTitle": "将 Claude Code 迁移到用户目录",
+        "migrationMessage": "Claude Code 安装在 npm 全局前缀中，更新需要管理员权限。可以将相同版本重新安装到：",
+        "migrate": "迁移",
+        "migrationDone": "Claude Code 已迁移，之后更新不再需要管理员权限。"
     },
     "zh-Hant": {
         "title": "Claude Code Easy Suite",
@@ -166,7 +317,79 @@ const translations: any = {
         "updateAvailable": "發現新版本: ",
         "foundNewVersion": "發現新版本",
         "downloadNow": "立即下載",
-        "paste": "貼上"
+        "paste": "貼上",
+        "tools": "工具",
+        "captures": "擷取記錄",
+        "noCaptures": "暫無記錄。開啟閘道擷取模式後會記錄請求。",
+        "back": "返回",
+        "markForDiff": "標記",
+        "unmark": "取消標記",
+        "compare": "對比",
+        "captureHint": "點擊一條記錄查看詳情。先標記一條記錄，再點擊另一條的「對比」查看差異。",
+        "apiKeys": "API 金鑰",
+        "requests": "請求數",
+        "failures": "失敗數",
+        "lastUsed": "最近使用",
+        "keyState": "狀態",
+        "keyReady": "可用",
+        "benchedUntil": "暫停至",
+        "noKeys": "尚未設定 API 金鑰。",
+        "queue": "請求佇列",
+        "inFlight": "進行中",
+        "queued": "排隊中",
+        "noQueue": "沒有模型設定並行上限。",
+        "keyCheck": "最近檢查",
+        "checkKeys": "立即檢查 Key",
+        "checking": "檢查中...",
+        "keyHealth_ok": "正常",
+        "keyHealth_invalid": "Key 無效",
+        "keyHealth_no_credit": "餘額不足",
+        "keyHealth_rate_limited": "被限流",
+        "keyHealth_error": "檢查失敗",
+        "keyHealth_unchecked": "未檢查",
+        "balance": "餘額",
+        "nodeOutdatedTitle": "Node.js 版本過低",
+        "nodeOutdatedMessage": "Claude Code 需要 Node.js {minimum} 或更高版本，但系統中的版本為 {version}：",
+        "nodePrivateHint": "可以在 ~/.cceasy/node 下為 Claude Code 單獨安裝一份 Node.js，系統中的 Node.js 不會被改動。",
+        "installPrivateNode": "安裝私有 Node.js",
+        "installing": "安裝中...",
+        "nodeInstalled": "Node.js 已安裝，Claude Code 將在背景繼續安裝。",
+        "later": "稍後",
+        "claudeUpdateTitle": "Claude Code 有新版本",
+        "claudeUpdateMessage": "Claude Code {latest} 已發布，目前安裝的版本為 {installed}。",
+        "claudeUpdateHint": "將 claude_code 中的 \"policy\" 設為 \"auto\" 可自動安裝新版本，設為 \"pinned\" 可固定在一個版本。",
+        "updateNow": "立即更新",
+        "claudeUpdated": "Claude Code {latest} 已安裝。",
+        "installedVersion": "已安裝版本",
+        "notInstalled": "未安裝",
+        "listVersions": "列出版本",
+        "install": "安裝",
+        "rollback": "回復",
+        "done": "完成",
+        "claudeVersionHint": "回復會重新安裝上一次安裝前的版本。",
+        "diagnostics": "環境診斷",
+        "recheck": "重新檢測",
+        "exportDiagnostics": "匯出",
+        "exportedTo": "已儲存到",
+        "fix_install_node": "執行環境檢測以安裝 Node.js。",
+        "fix_node_too_old": "需要 Node.js {minimum} 或更高版本。可在環境檢測中將私有 Node.js 安裝到 {dir}。",
+        "fix_reinstall_node": "npm 隨 Node.js 一起安裝。請在環境檢測中重新安裝 Node.js。",
+        "fix_check_npm_prefix": "請確認在終端機中可以執行 `npm config get prefix`。",
+        "fix_install_claude": "執行環境檢測以安裝 Claude Code。",
+        "fix_claude_not_on_path": "Claude Code 已安裝，但其目錄不在 PATH 中。請將 npm 全域 bin 目錄加入 PATH。",
+        "fix_install_pinned_claude": "已固定版本 {version}。執行環境檢測以安裝該版本。",
+        "fix_install_git_windows": "Claude Code 需要 Git for Windows。執行環境檢測以安裝。",
+        "fix_install_git": "請用系統套件管理員安裝 git，以便 Claude Code 操作程式碼倉庫。",
+        "fix_install_terminal": "Claude Code 在終端機視窗中啟動。請安裝一個終端機模擬器。",
+        "fix_anthropic_env_override": "環境變數中設定了 {name}，可能會覆蓋所選模型。請從 shell 設定檔或系統環境變數中刪除。",
+        "fix_check_parent_dir": "請檢查上層目錄的權限。",
+        "fix_file_not_writable_windows": "cceasy 無法寫入此處。請在檔案「內容 > 安全性」中為目前使用者授予寫入權限。",
+        "fix_file_not_writable": "cceasy 無法寫入此處。請將擁有者改為目前使用者，例如 `chown $USER {path}`。",
+        "fix_file_group_writable": "其他使用者可以修改此檔案。請執行 `chmod go-w {path}`。",
+        "migrationTitle": "將 Claude Code 遷移到使用者目錄",
+        "migrationMessage": "Claude Code 安裝在 npm 全域前綴中，更新需要系統管理員權限。可以將相同版本重新安裝到：",
+        "migrate": "遷移",
+        "migrationDone": "Claude Code 已遷移，之後更新不再需要系統管理員權限。"
     },
     "ko": {
         "title": "Claude Code Easy Suite",
@@ -367,6 +590,18 @@ function App() {
     const [tempProjects, setTempProjects] = useState<any[]>([]); // Local state for project manager
     const [managerStatus, setManagerStatus] = useState("");
     const [lang, setLang] = useState("en");
+    const [showTools, setShowTools] = useState(false);
+    const [queue, setQueue] = useState<main.QueueStatus[]>([]);
+    const [balances, setBalances] = useState<main.Balance[]>([]);
+    const [nodeOutdated, setNodeOutdated] = useState<NodeOutdated | null>(null);
+    const [nodeInstall, setNodeInstall] = useState<"" | "installing" | "done" | "error">("");
+    const [nodeInstallError, setNodeInstallError] = useState("");
+    const [claudeUpdate, setClaudeUpdate] = useState<ClaudeUpdate | null>(null);
+    const [claudeInstall, setClaudeInstall] = useState<"" | "installing" | "done" | "error">("");
+    const [claudeInstallError, setClaudeInstallError] = useState("");
+    const [migrationPrefix, setMigrationPrefix] = useState("");
+    const [migration, setMigration] = useState<"" | "migrating" | "done" | "error">("");
+    const [migrationError, setMigrationError] = useState("");
 
     // Recover Modal State
     const [showRecoverModal, setShowRecoverModal] = useState(false);
@@ -422,6 +657,12 @@ function App() {
 
         EventsOn("env-log", logHandler);
         EventsOn("env-check-done", doneHandler);
+        // The system Node.js is too old; offer a private copy instead
+        EventsOn("node-outdated", (info: NodeOutdated) => setNodeOutdated(info));
+        // A new Claude Code release under the "notify" policy
+        EventsOn("claude-update-available", (info: ClaudeUpdate) => setClaudeUpdate(info));
+        // Claude Code sits in a global prefix that needs administrator rights
+        EventsOn("claude-migration-available", (prefix: string) => setMigrationPrefix(prefix));
 
         CheckEnvironment(); // Start checks
 
@@ -449,10 +690,21 @@ function App() {
         };
         EventsOn("config-changed", handleConfigChange);
 
+        // Requests waiting for a concurrency slot in the gateway
+        GetQueueStatus().then(list => setQueue(list || []));
+        EventsOn("gateway-queue", (list: main.QueueStatus[]) => setQueue(list || []));
+
+        // Remaining credit, refreshed in the background by the backend
+        EventsOn("balances", (list: main.Balance[]) => setBalances(list || []));
+        GetBalances();
+
         return () => {
             EventsOff("config-changed");
+            EventsOff("gateway-queue");
+            EventsOff("balances");
             EventsOff("env-log");
             EventsOff("env-check-done");
+            EventsOff("node-outdated");
         };
     }, []);
 
@@ -705,6 +957,55 @@ function App() {
         });
     };
 
+    const handleInstallPrivateNode = () => {
+        setNodeInstall("installing");
+        InstallPrivateNode().then(() => {
+            setNodeInstall("done");
+        }).catch((err) => {
+            setNodeInstallError(String(err));
+            setNodeInstall("error");
+        });
+    };
+
+    const closeNodeOutdated = () => {
+        setNodeOutdated(null);
+        setNodeInstall("");
+        setNodeInstallError("");
+    };
+
+    const handleClaudeUpdate = () => {
+        if (!claudeUpdate) return;
+        setClaudeInstall("installing");
+        InstallClaudeVersion(claudeUpdate.latest).then(() => {
+            setClaudeInstall("done");
+        }).catch((err) => {
+            setClaudeInstallError(String(err));
+            setClaudeInstall("error");
+        });
+    };
+
+    const closeClaudeUpdate = () => {
+        setClaudeUpdate(null);
+        setClaudeInstall("");
+        setClaudeInstallError("");
+    };
+
+    const handleMigrateClaude = () => {
+        setMigration("migrating");
+        MigrateClaudeToUserPrefix().then(() => {
+            setMigration("done");
+        }).catch((err) => {
+            setMigrationError(String(err));
+            setMigration("error");
+        });
+    };
+
+    const closeMigration = () => {
+        setMigrationPrefix("");
+        setMigration("");
+        setMigrationError("");
+    };
+
     if (isLoading) {
         return (
             <div style={{
@@ -907,6 +1208,19 @@ function App() {
                     border: '1px solid rgba(251, 146, 60, 0.1)'
                 }}>
                     <div style={{position: 'relative', display: 'flex', justifyContent: 'center', alignItems: 'center', marginBottom: '5px'}}>
+                        <button 
+                            className="btn-link" 
+                            onClick={() => setShowTools(true)}
+                            style={{
+                                position: 'absolute', 
+                                left: '10px', 
+                                borderColor: '#fb923c', 
+                                color: '#fb923c',
+                                fontSize: '0.8rem'
+                            }}
+                        >
+                            🛠 {t("tools")}
+                        </button>
                         <h3 style={{fontSize: '1.1rem', color: '#fb923c', textTransform: 'uppercase', letterSpacing: '0.05em', marginBottom: '5px', marginTop: '-5px', textAlign: 'center'}}>{t("activeModel")}</h3>
                         <button 
                             className="btn-link" 
@@ -923,19 +1237,38 @@ function App() {
                         </button>
                     </div>
                     <div className="model-switcher" style={{justifyContent: 'center', padding: '0 10px', marginBottom: 0}}>
-                        {config.models.map((model) => (
-                            <button
-                                key={model.model_name}
-                                className={`model-btn ${config.current_model === model.model_name ? 'selected' : ''}`}
-                                onClick={() => handleModelSwitch(model.model_name)}
-                                style={{
-                                    textAlign: 'center',
-                                    borderBottom: (model.api_key && model.api_key.trim() !== "") ? '3px solid #fb923c' : '1px solid var(--border-color)'
-                                }}
-                            >
-                                {model.model_name}
-                            </button>
-                        ))}
+                        {config.models.map((model) => {
+                            const q = queue.find(item => item.model_name === model.model_name);
+                            const busy = q && (q.active > 0 || q.queued > 0);
+                            const balance = balances.find(item => item.model_name === model.model_name);
+                            return (
+                                <button
+                                    key={model.model_name}
+                                    className={`model-btn ${config.current_model === model.model_name ? 'selected' : ''}`}
+                                    onClick={() => handleModelSwitch(model.model_name)}
+                                    title={[
+                                        q ? `${t("inFlight")}: ${q.active}/${q.max_concurrency}, ${t("queued")}: ${q.queued}` : "",
+                                        balance ? (balance.error ? `${t("balance")}: ${balance.error}` : `${t("balance")}: ${balance.amount.toFixed(2)} ${balance.currency}`) : ""
+                                    ].filter(Boolean).join("\n") || undefined}
+                                    style={{
+                                        textAlign: 'center',
+                                        borderBottom: (model.api_key && model.api_key.trim() !== "") ? '3px solid #fb923c' : '1px solid var(--border-color)'
+                                    }}
+                                >
+                                    {model.model_name}
+                                    {busy && (
+                                        <span style={{marginLeft: '4px', fontSize: '0.7rem', color: q.queued > 0 ? '#ef4444' : '#6b7280'}}>
+                                            {q.active}/{q.max_concurrency}{q.queued > 0 ? ` +${q.queued}` : ""}
+                                        </span>
+                                    )}
+                                    {balance && !balance.error && (
+                                        <span style={{display: 'block', fontSize: '0.7rem', color: balance.low ? '#ef4444' : '#6b7280'}}>
+                                            {balance.amount.toFixed(2)} {balance.currency}
+                                        </span>
+                                    )}
+                                </button>
+                            );
+                        })}
                     </div>
                 </div>
 
@@ -1377,6 +1710,131 @@ function App() {
                 </div>
             )}
 
+            {showTools && <ToolsModal t={t} onClose={() => setShowTools(false)} />}
+
+            {nodeOutdated && (
+                <div className="modal-overlay">
+                    <div className="modal-content" onClick={e => e.stopPropagation()} style={{width: '400px', textAlign: 'left'}}>
+                        {nodeInstall !== "installing" && (
+                            <button className="modal-close" onClick={closeNodeOutdated}>&times;</button>
+                        )}
+                        <h3 style={{marginTop: 0, color: '#fb923c'}}>{t("nodeOutdatedTitle")}</h3>
+                        <p style={{fontSize: '0.9rem', color: '#4b5563'}}>
+                            {t("nodeOutdatedMessage").replace("{version}", nodeOutdated.version).replace("{minimum}", nodeOutdated.minimum)}
+                        </p>
+                        <div style={{fontSize: '0.8rem', color: '#6b7280', fontFamily: 'monospace', wordBreak: 'break-all', marginBottom: '10px'}}>
+                            {nodeOutdated.path}
+                        </div>
+                        <p style={{fontSize: '0.85rem', color: '#6b7280'}}>{t("nodePrivateHint")}</p>
+                        {nodeInstall === "installing" && (
+                            <div style={{fontSize: '0.8rem', color: '#6b7280', whiteSpace: 'nowrap', overflow: 'hidden', textOverflow: 'ellipsis', marginBottom: '10px'}}>
+                                {envLogs[envLogs.length - 1]}
+                            </div>
+                        )}
+                        {nodeInstall === "done" && (
+                            <div style={{fontSize: '0.85rem', color: '#10b981', marginBottom: '10px'}}>{t("nodeInstalled")}</div>
+                        )}
+                        {nodeInstall === "error" && (
+                            <div style={{fontSize: '0.85rem', color: '#ef4444', marginBottom: '10px', wordBreak: 'break-word'}}>{nodeInstallError}</div>
+                        )}
+                        <div style={{display: 'flex', gap: '10px'}}>
+                            {nodeInstall === "done" ? (
+                                <button className="btn-primary" style={{flex: 1}} onClick={closeNodeOutdated}>OK</button>
+                            ) : (
+                                <>
+                                    <button className="btn-primary" style={{flex: 1}} disabled={nodeInstall === "installing"} onClick={handleInstallPrivateNode}>
+                                        {nodeInstall === "installing" ? t("installing") : t("installPrivateNode")}
+                                    </button>
+                                    <button className="btn-primary" style={{flex: 1, backgroundColor: '#6b7280'}} disabled={nodeInstall === "installing"} onClick={closeNodeOutdated}>
+                                        {t("later")}
+                                    </button>
+                                </>
+                            )}
+                        </div>
+                    </div>
+                </div>
+            )}
+
+            {migrationPrefix && !nodeOutdated && (
+                <div className="modal-overlay">
+                    <div className="modal-content" onClick={e => e.stopPropagation()} style={{width: '400px', textAlign: 'left'}}>
+                        {migration !== "migrating" && (
+                            <button className="modal-close" onClick={closeMigration}>&times;</button>
+                        )}
+                        <h3 style={{marginTop: 0, color: '#fb923c'}}>{t("migrationTitle")}</h3>
+                        <p style={{fontSize: '0.9rem', color: '#4b5563'}}>{t("migrationMessage")}</p>
+                        <div style={{fontSize: '0.8rem', color: '#6b7280', fontFamily: 'monospace', wordBreak: 'break-all', marginBottom: '10px'}}>
+                            {migrationPrefix}
+                        </div>
+                        {migration === "migrating" && (
+                            <div style={{fontSize: '0.8rem', color: '#6b7280', whiteSpace: 'nowrap', overflow: 'hidden', textOverflow: 'ellipsis', marginBottom: '10px'}}>
+                                {envLogs[envLogs.length - 1]}
+                            </div>
+                        )}
+                        {migration === "done" && (
+                            <div style={{fontSize: '0.85rem', color: '#10b981', marginBottom: '10px'}}>{t("migrationDone")}</div>
+                        )}
+                        {migration === "error" && (
+                            <div style={{fontSize: '0.85rem', color: '#ef4444', marginBottom: '10px', wordBreak: 'break-word'}}>{migrationError}</div>
+                        )}
+                        <div style={{display: 'flex', gap: '10px'}}>
+                            {migration === "done" ? (
+                                <button className="btn-primary" style={{flex: 1}} onClick={closeMigration}>OK</button>
+                            ) : (
+                                <>
+                                    <button className="btn-primary" style={{flex: 1}} disabled={migration === "migrating"} onClick={handleMigrateClaude}>
+                                        {migration === "migrating" ? t("installing") : t("migrate")}
+                                    </button>
+                                    <button className="btn-primary" style={{flex: 1, backgroundColor: '#6b7280'}} disabled={migration === "migrating"} onClick={closeMigration}>
+                                        {t("later")}
+                                    </button>
+                                </>
+                            )}
+                        </div>
+                    </div>
+                </div>
+            )}
+
+            {claudeUpdate && !nodeOutdated && !migrationPrefix && (
+                <div className="modal-overlay">
+                    <div className="modal-content" onClick={e => e.stopPropagation()} style={{width: '400px', textAlign: 'left'}}>
+                        {claudeInstall !== "installing" && (
+                            <button className="modal-close" onClick={closeClaudeUpdate}>&times;</button>
+                        )}
+                        <h3 style={{marginTop: 0, color: '#fb923c'}}>{t("claudeUpdateTitle")}</h3>
+                        <p style={{fontSize: '0.9rem', color: '#4b5563'}}>
+                            {t("claudeUpdateMessage").replace("{latest}", claudeUpdate.latest).replace("{installed}", claudeUpdate.installed)}
+                        </p>
+                        <p style={{fontSize: '0.85rem', color: '#6b7280'}}>{t("claudeUpdateHint")}</p>
+                        {claudeInstall === "installing" && (
+                            <div style={{fontSize: '0.8rem', color: '#6b7280', whiteSpace: 'nowrap', overflow: 'hidden', textOverflow: 'ellipsis', marginBottom: '10px'}}>
+                                {envLogs[envLogs.length - 1]}
+                            </div>
+                        )}
+                        {claudeInstall === "done" && (
+                            <div style={{fontSize: '0.85rem', color: '#10b981', marginBottom: '10px'}}>{t("claudeUpdated").replace("{latest}", claudeUpdate.latest)}</div>
+                        )}
+                        {claudeInstall === "error" && (
+                            <div style={{fontSize: '0.85rem', color: '#ef4444', marginBottom: '10px', wordBreak: 'break-word'}}>{claudeInstallError}</div>
+                        )}
+                        <div style={{display: 'flex', gap: '10px'}}>
+                            {claudeInstall === "done" ? (
+                                <button className="btn-primary" style={{flex: 1}} onClick={closeClaudeUpdate}>OK</button>
+                            ) : (
+                                <>
+                                    <button className="btn-primary" style={{flex: 1}} disabled={claudeInstall === "installing"} onClick={handleClaudeUpdate}>
+                                        {claudeInstall === "installing" ? t("installing") : t("updateNow")}
+                                    </button>
+                                    <button className="btn-primary" style={{flex: 1, backgroundColor: '#6b7280'}} disabled={claudeInstall === "installing"} onClick={closeClaudeUpdate}>
+                                        {t("later")}
+                                    </button>
+                                </>
+                            )}
+                        </div>
+                    </div>
+                </div>
+            )}
+
             {showAbout && (
                 <div className="modal-overlay" onClick={(e) => { if (e.target === e.currentTarget) setShowAbout(false); }}>
                     <div className="modal-content" onClick={e => e.stopPropagation()} style={{textAlign: 'center'}}>
@@ -0,0 +1,488 @@
+import {useEffect, useState} from 'react';
+import {ListCaptureSessions, LoadCaptureSession, DeleteCaptureSession, DiffCaptureRecords, GetKeyStatus, GetQueueStatus, GetKeyHealth, CheckKeys, GetClaudeVersion, ListClaudeVersions, InstallClaudeVersion, RollbackClaude, DiagnoseEnvironment, ExportDiagnostics} from "../wailsjs/go/main/App";
+import {EventsOn} from "../wailsjs/runtime";
+import {main} from "../wailsjs/go/models";
+
+type Translate = (key: string) => string;
+
+const listBox: React.CSSProperties = {
+    border: '1px solid #ffedd5',
+    borderRadius: '8px',
+    overflowY: 'auto',
+    backgroundColor: '#fffdfa'
+};
+
+const codeBox: React.CSSProperties = {
+    margin: 0,
+    height: '260px',
+    overflow: 'auto',
+    padding: '10px',
+    fontSize: '0.8rem',
+    fontFamily: 'monospace',
+    whiteSpace: 'pre',
+    backgroundColor: '#1e1e1e',
+    color: '#e5e5e5',
+    borderRadius: '6px'
+};
+
+const rowStyle = (selected: boolean): React.CSSProperties => ({
+    padding: '6px 8px',
+    fontSize: '0.8rem',
+    cursor: 'pointer',
+    borderBottom: '1px solid #ffedd5',
+    backgroundColor: selected ? '#ffedd5' : 'transparent'
+});
+
+const cellStyle: React.CSSProperties = {
+    padding: '6px 8px',
+    fontSize: '0.8rem',
+    borderBottom: '1px solid #ffedd5',
+    whiteSpace: 'nowrap'
+};
+
+const formatTime = (value: string) => value ? new Date(value).toLocaleTimeString() : "-";
+
+const formatSize = (bytes: number) => {
+    if (bytes >= 1024 * 1024) return (bytes / 1024 / 1024).toFixed(1) + " MB";
+    if (bytes >= 1024) return (bytes / 1024).toFixed(1) + " KB";
+    return bytes + " B";
+};
+
+const diffLineColor = (line: string) => {
+    if (line.startsWith("+++") || line.startsWith("---")) return '#fbbf24';
+    if (line.startsWith("+ ")) return '#4ade80';
+    if (line.startsWith("- ")) return '#f87171';
+    return '#9ca3af';
+};
+
+// CapturesTab browses the sessions written by gateway capture mode and
+// diffs two exchanges, e.g. the same prompt sent to two providers.
+function CapturesTab({t}: {t: Translate}) {
+    const [sessions, setSessions] = useState<main.CaptureSession[]>([]);
+    const [session, setSession] = useState("");
+    const [records, setRecords] = useState<main.CaptureRecord[]>([]);
+    const [record, setRecord] = useState<main.CaptureRecord | null>(null);
+    const [base, setBase] = useState<{session: string, id: string} | null>(null);
+    const [diff, setDiff] = useState("");
+    const [error, setError] = useState("");
+
+    const refresh = () => {
+        ListCaptureSessions().then(list => setSessions(list || [])).catch(err => setError(String(err)));
+    };
+
+    useEffect(() => {
+        refresh();
+    }, []);
+
+    const openSession = (id: string) => {
+        setSession(id);
+        setRecord(null);
+        setError("");
+        LoadCaptureSession(id).then(list => setRecords(list || [])).catch(err => setError(String(err)));
+    };
+
+    const removeSession = (id: string) => {
+        DeleteCaptureSession(id).then(() => {
+            if (session === id) {
+                setSession("");
+                setRecords([]);
+                setRecord(null);
+            }
+            if (base && base.session === id) setBase(null);
+            refresh();
+        }).catch(err => setError(String(err)));
+    };
+
+    const compareWith = (r: main.CaptureRecord) => {
+        if (!base) return;
+        DiffCaptureRecords(base.session, base.id, session, r.id).then(text => {
+            setDiff(text);
+        }).catch(err => setError(String(err)));
+    };
+
+    if (diff) {
+        return (
+            <div>
+                <button className="btn-link" style={{marginBottom: '8px'}} onClick={() => setDiff("")}>◀ {t("back")}</button>
+                <div style={codeBox}>
+                    {diff.split("\n").map((line, i) => (
+                        <div key={i} style={{color: diffLineColor(line)}}>{line}</div>
+                    ))}
+                </div>
+            </div>
+        );
+    }
+
+    if (record) {
+        const detail = {
+            request_headers: record.request_headers,
+            request: record.request,
+            response_headers: record.response_headers,
+            response: record.response,
+            error: record.error
+        };
+        return (
+            <div>
+                <div style={{display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: '8px'}}>
+                    <button className="btn-link" onClick={() => setRecord(null)}>◀ {t("back")}</button>
+                    <span style={{fontSize: '0.8rem', color: '#6b7280'}}>
+                        {record.provider} · {record.method} {record.path} · {record.status || "-"} · {record.duration_ms} ms
+                    </span>
+                </div>
+                <pre style={codeBox}>{JSON.stringify(detail, null, 2)}</pre>
+            </div>
+        );
+    }
+
+    return (
+        <div>
+            {error && <div style={{color: '#ef4444', fontSize: '0.8rem', marginBottom: '8px'}}>{error}</div>}
+            <div style={{display: 'flex', gap: '10px', height: '280px'}}>
+                <div style={{...listBox, width: '40%'}}>
+                    {sessions.length === 0 && (
+                        <div style={{padding: '10px', fontSize: '0.8rem', color: '#6b7280'}}>{t("noCaptures")}</div>
+                    )}
+                    {sessions.map(s => (
+                        <div key={s.id} style={{...rowStyle(s.id === session), display: 'flex', justifyContent: 'space-between', gap: '6px'}} onClick={() => openSession(s.id)}>
+                            <div style={{overflow: 'hidden'}}>
+                                <div style={{fontWeight: 600, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap'}}>{s.id}</div>
+                                <div style={{color: '#6b7280'}}>{s.records} · {formatSize(s.size)}</div>
+                            </div>
+                            <button
+                                className="btn-link"
+                                style={{color: '#ef4444', borderColor: '#ef4444', padding: '0 6px', alignSelf: 'center'}}
+                                onClick={(e) => { e.stopPropagation(); removeSession(s.id); }}
+                            >
+                                {t("delete")}
+                            </button>
+                        </div>
+                    ))}
+                </div>
+                <div style={{...listBox, flex: 1}}>
+                    {records.map(r => {
+                        const isBase = base !== null && base.session === session && base.id === r.id;
+                        return (
+                            <div key={r.id} style={{...rowStyle(isBase), display: 'flex', justifyContent: 'space-between', gap: '6px'}} onClick={() => setRecord(r)}>
+                                <div style={{overflow: 'hidden', whiteSpace: 'nowrap', textOverflow: 'ellipsis'}}>
+                                    <span style={{color: r.status >= 400 || r.error ? '#ef4444' : '#10b981', marginRight: '6px'}}>{r.status || "ERR"}</span>
+                                    {r.provider} {r.path}
+                                </div>
+                                <div style={{display: 'flex', gap: '4px'}} onClick={e => e.stopPropagation()}>
+                                    {base && !isBase && (
+                                        <button className="btn-link" style={{padding: '0 6px'}} onClick={() => compareWith(r)}>{t("compare")}</button>
+                                    )}
+                                    <button className="btn-link" style={{padding: '0 6px'}} onClick={() => setBase(isBase ? null : {session: session, id: r.id})}>
+                                        {isBase ? t("unmark") : t("markForDiff")}
+                                    </button>
+                                </div>
+                            </div>
+                        );
+                    })}
+                </div>
+            </div>
+            <div style={{fontSize: '0.75rem', color: '#6b7280', marginTop: '6px'}}>{t("captureHint")}</div>
+        </div>
+    );
+}
+
+// KeysTab shows how the gateway spreads requests over each model's keys.
+function KeysTab({t}: {t: Translate}) {
+    const [keys, setKeys] = useState<main.KeyStatus[]>([]);
+    const [health, setHealth] = useState<main.KeyHealth[]>([]);
+    const [checking, setChecking] = useState(false);
+
+    useEffect(() => {
+        const refresh = () => GetKeyStatus().then(list => setKeys(list || []));
+        refresh();
+        const timer = setInterval(refresh, 5000);
+        GetKeyHealth().then(list => setHealth(list || []));
+        const off = EventsOn("key-health", (list: main.KeyHealth[]) => setHealth(list || []));
+        return () => {
+            clearInterval(timer);
+            off();
+        };
+    }, []);
+
+    const checkNow = () => {
+        setChecking(true);
+        CheckKeys().then(list => setHealth(list || [])).finally(() => setChecking(false));
+    };
+
+    const healthColor = (status: string) => {
+        if (status === "ok") return '#10b981';
+        if (status === "invalid" || status === "no_credit") return '#ef4444';
+        if (status === "unchecked") return '#6b7280';
+        return '#f59e0b';
+    };
+
+    return (
+        <div>
+            <div style={{...listBox, height: '270px'}}>
+                <table style={{width: '100%', borderCollapse: 'collapse'}}>
+                    <thead>
+                        <tr style={{color: '#fb923c', textAlign: 'left'}}>
+                            <th style={cellStyle}>{t("modelName")}</th>
+                            <th style={cellStyle}>{t("apiKey")}</th>
+                            <th style={cellStyle}>{t("requests")}</th>
+                            <th style={cellStyle}>{t("failures")}</th>
+                            <th style={cellStyle}>429</th>
+                            <th style={cellStyle}>{t("lastUsed")}</th>
+                            <th style={cellStyle}>{t("keyState")}</th>
+                            <th style={cellStyle}>{t("keyCheck")}</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {keys.map(k => {
+                            const h = health.find(item => item.model_name === k.model_name && item.key === k.key);
+                            return (
+                                <tr key={k.model_name + k.key}>
+                                    <td style={cellStyle}>{k.model_name}</td>
+                                    <td style={{...cellStyle, fontFamily: 'monospace'}}>{k.key}</td>
+                                    <td style={cellStyle}>{k.requests}</td>
+                                    <td style={cellStyle}>{k.failures}</td>
+                                    <td style={cellStyle}>{k.rate_limited}</td>
+                                    <td style={cellStyle}>{formatTime(k.last_used)}{k.last_status ? ` (${k.last_status})` : ""}</td>
+                                    <td style={{...cellStyle, color: k.benched_until ? '#ef4444' : '#10b981'}}>
+                                        {k.benched_until ? `${t("benchedUntil")} ${formatTime(k.benched_until)}` : t("keyReady")}
+                                    </td>
+                                    <td style={{...cellStyle, color: h ? healthColor(h.status) : '#6b7280'}} title={h ? h.message : undefined}>
+                                        {h ? t("keyHealth_" + h.status) : "-"}
+                                    </td>
+                                </tr>
+                            );
+                        })}
+                    </tbody>
+                </table>
+                {keys.length === 0 && (
+                    <div style={{padding: '10px', fontSize: '0.8rem', color: '#6b7280'}}>{t("noKeys")}</div>
+                )}
+            </div>
+            <div style={{marginTop: '8px', textAlign: 'right'}}>
+                <button className="btn-link" onClick={checkNow} disabled={checking}>
+                    {checking ? t("checking") : t("checkKeys")}
+                </button>
+            </div>
+        </div>
+    );
+}
+
+// QueueTab shows requests holding or waiting for a concurrency slot, for
+// models with max_concurrency set.
+function QueueTab({t}: {t: Translate}) {
+    const [queue, setQueue] = useState<main.QueueStatus[]>([]);
+
+    useEffect(() => {
+        GetQueueStatus().then(list => setQueue(list || []));
+        // Unsubscribe only this listener; the main window listens too
+        return EventsOn("gateway-queue", (list: main.QueueStatus[]) => setQueue(list || []));
+    }, []);
+
+    return (
+        <div style={{...listBox, height: '300px'}}>
+            <table style={{width: '100%', borderCollapse: 'collapse'}}>
+                <thead>
+                    <tr style={{color: '#fb923c', textAlign: 'left'}}>
+                        <th style={cellStyle}>{t("modelName")}</th>
+                        <th style={cellStyle}>{t("inFlight")}</th>
+                        <th style={cellStyle}>{t("queued")}</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {queue.map(q => (
+                        <tr key={q.model_name}>
+                            <td style={cellStyle}>{q.model_name}</td>
+                            <td style={cellStyle}>{q.active} / {q.max_concurrency}</td>
+                            <td style={{...cellStyle, color: q.queued > 0 ? '#ef4444' : undefined}}>{q.queued}</td>
+                        </tr>
+                    ))}
+                </tbody>
+            </table>
+            {queue.length === 0 && (
+                <div style={{padding: '10px', fontSize: '0.8rem', color: '#6b7280'}}>{t("noQueue")}</div>
+            )}
+        </div>
+    );
+}
+
+// ClaudeTab shows the installed Claude Code version and installs another
+// one from the npm registry, or rolls back to the one replaced last.
+function ClaudeTab({t}: {t: Translate}) {
+    const [installed, setInstalled] = useState("");
+    const [versions, setVersions] = useState<string[]>([]);
+    const [version, setVersion] = useState("");
+    const [busy, setBusy] = useState(false);
+    const [status, setStatus] = useState("");
+    const [error, setError] = useState("");
+
+    const refresh = () => {
+        GetClaudeVersion().then(v => setInstalled(v)).catch(err => setError(String(err)));
+    };
+
+    useEffect(() => {
+        refresh();
+    }, []);
+
+    const listVersions = () => {
+        setBusy(true);
+        setError("");
+        ListClaudeVersions().then(list => {
+            setVersions(list || []);
+            if (list && list.length > 0) setVersion(list[0]);
+        }).catch(err => setError(String(err))).finally(() => setBusy(false));
+    };
+
+    const run = (action: Promise<any>) => {
+        setBusy(true);
+        setStatus("");
+        setError("");
+        action.then(() => {
+            setStatus(t("done"));
+            refresh();
+        }).catch(err => setError(String(err))).finally(() => setBusy(false));
+    };
+
+    return (
+        <div style={{fontSize: '0.85rem'}}>
+            <div style={{marginBottom: '12px'}}>
+                {t("installedVersion")}: <b>{installed || t("notInstalled")}</b>
+            </div>
+            <div style={{display: 'flex', gap: '8px', alignItems: 'center', marginBottom: '12px'}}>
+                <button className="btn-link" onClick={listVersions} disabled={busy}>{t("listVersions")}</button>
+                {versions.length > 0 && (
+                    <>
+                        <select value={version} onChange={e => setVersion(e.target.value)} disabled={busy}>
+                            {versions.map(v => <option key={v} value={v}>{v}</option>)}
+                        </select>
+                        <button className="btn-link" onClick={() => run(InstallClaudeVersion(version))} disabled={busy || !version || version === installed}>
+                            {t("install")}
+                        </button>
+                    </>
+                )}
+                <button className="btn-link" onClick={() => run(RollbackClaude())} disabled={busy}>{t("rollback")}</button>
+            </div>
+            {busy && <div style={{color: '#6b7280'}}>{t("installing")}</div>}
+            {status && !busy && <div style={{color: '#10b981'}}>{status}</div>}
+            {error && <div style={{color: '#ef4444', wordBreak: 'break-word'}}>{error}</div>}
+            <div style={{fontSize: '0.75rem', color: '#6b7280', marginTop: '12px'}}>{t("claudeVersionHint")}</div>
+        </div>
+    );
+}
+
+const severityColor: {[severity: string]: string} = {
+    ok: '#10b981',
+    info: '#6b7280',
+    warning: '#f59e0b',
+    error: '#ef4444'
+};
+
+const severityIcon: {[severity: string]: string} = {
+    ok: '✓',
+    info: 'i',
+    warning: '!',
+    error: '✗'
+};
+
+// DiagnosticsTab lists what Claude Code depends on as a checklist, with the
+// suggested fix for each problem in the user's language.
+function DiagnosticsTab({t}: {t: Translate}) {
+    const [report, setReport] = useState<main.DiagnosticReport | null>(null);
+    const [loading, setLoading] = useState(false);
+    const [exported, setExported] = useState("");
+    const [error, setError] = useState("");
+
+    const refresh = () => {
+        setLoading(true);
+        setError("");
+        DiagnoseEnvironment().then(r => setReport(r)).catch(err => setError(String(err))).finally(() => setLoading(false));
+    };
+
+    useEffect(() => {
+        refresh();
+    }, []);
+
+    const exportReport = () => {
+        setError("");
+        ExportDiagnostics().then(path => setExported(path)).catch(err => setError(String(err)));
+    };
+
+    // Fixes without a translation fall back to the English text from the backend
+    const fixText = (item: main.DiagnosticItem) => {
+        const key = "fix_" + item.fix_code;
+        if (!item.fix_code || t(key) === key) return item.fix || "";
+        let text = t(key);
+        Object.entries(item.fix_params || {}).forEach(([name, value]) => {
+            text = text.split("{" + name + "}").join(value);
+        });
+        return text;
+    };
+
+    return (
+        <div>
+            <div style={{...listBox, height: '270px'}}>
+                {loading && !report && (
+                    <div style={{padding: '10px', fontSize: '0.8rem', color: '#6b7280'}}>{t("checking")}</div>
+                )}
+                {report && report.items.map(item => (
+                    <div key={item.id} style={{...cellStyle, whiteSpace: 'normal', display: 'flex', gap: '8px'}}>
+                        <span style={{color: severityColor[item.severity], fontWeight: 700, width: '12px', textAlign: 'center'}}>
+                            {severityIcon[item.severity]}
+                        </span>
+                        <div style={{overflow: 'hidden', flex: 1}}>
+                            <div>
+                                <b>{item.title}</b>
+                                <span style={{color: '#6b7280', fontFamily: 'monospace', marginLeft: '8px', wordBreak: 'break-all'}}>{item.value}</span>
+                            </div>
+                            {(item.fix || item.fix_code) && (
+                                <div style={{color: severityColor[item.severity]}}>{fixText(item)}</div>
+                            )}
+                        </div>
+                    </div>
+                ))}
+            </div>
+            {error && <div style={{color: '#ef4444', fontSize: '0.8rem', marginTop: '6px'}}>{error}</div>}
+            {exported && <div style={{color: '#10b981', fontSize: '0.8rem', marginTop: '6px', wordBreak: 'break-all'}}>{t("exportedTo")} {exported}</div>}
+            <div style={{marginTop: '8px', display: 'flex', justifyContent: 'flex-end', gap: '8px'}}>
+                <button className="btn-link" onClick={refresh} disabled={loading}>{loading ? t("checking") : t("recheck")}</button>
+                <button className="btn-link" onClick={exportReport} disabled={!report}>{t("exportDiagnostics")}</button>
+            </div>
+        </div>
+    );
+}
+
+// ToolsModal groups the gateway and environment tools that only advanced
+// users need, keeping the main window unchanged.
+export function ToolsModal({t, onClose}: {t: Translate, onClose: () => void}) {
+    const tabs = [
+        {key: "captures", label: t("captures")},
+        {key: "keys", label: t("apiKeys")},
+        {key: "queue", label: t("queue")},
+        {key: "claude", label: "Claude Code"},
+        {key: "diagnostics", label: t("diagnostics")}
+    ];
+    const [tab, setTab] = useState(tabs[0].key);
+
+    return (
+        <div className="modal-overlay" onClick={(e) => { if (e.target === e.currentTarget) onClose(); }}>
+            <div className="modal-content" onClick={e => e.stopPropagation()} style={{width: '680px', textAlign: 'left'}}>
+                <button className="modal-close" onClick={onClose}>&times;</button>
+                <h3 style={{marginTop: 0, color: '#fb923c', marginBottom: '10px'}}>{t("tools")}</h3>
+                <div className="tabs" style={{marginBottom: '10px'}}>
+                    {tabs.map(item => (
+                        <button
+                            key={item.key}
+                            className={`tab-button ${tab === item.key ? 'active' : ''}`}
+                            onClick={() => setTab(item.key)}
+                        >
+                            {item.label}
+                        </button>
+                    ))}
+                </div>
+                {tab === "captures" && <CapturesTab t={t} />}
+                {tab === "keys" && <KeysTab t={t} />}
+                {tab === "queue" && <QueueTab t={t} />}
+                {tab === "claude" && <ClaudeTab t={t} />}
+                {tab === "diagnostics" && <DiagnosticsTab t={t} />}
+            </div>
+        </div>
+    );
+}
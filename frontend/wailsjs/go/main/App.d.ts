@@ -2,22 +2,72 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function CancelDownload():Promise<void>;
+
 export function CheckEnvironment():Promise<void>;
 
+export function CheckKeys():Promise<main.KeyHealth[]>;
+
+export function CheckOfflineBundle(arg1:string):Promise<main.BundleManifest>;
+
 export function CheckUpdate(arg1:string):Promise<main.UpdateResult>;
 
+export function CreateOfflineBundle(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function DeleteCaptureSession(arg1:string):Promise<void>;
+
+export function DetectFastestMirrors():Promise<main.MirrorConfig>;
+
+export function DiagnoseEnvironment():Promise<main.DiagnosticReport>;
+
+export function DiffCaptureRecords(arg1:string,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function DiscoverLocalModels():Promise<main.ModelConfig[]>;
+
+export function ExportDiagnostics():Promise<string>;
+
+export function GetBalances():Promise<main.Balance[]>;
+
+export function GetClaudeVersion():Promise<string>;
+
+export function GetKeyHealth():Promise<main.KeyHealth[]>;
+
+export function GetKeyStatus():Promise<main.KeyStatus[]>;
+
+export function GetQueueStatus():Promise<main.QueueStatus[]>;
+
+export function GetUsage():Promise<main.ModelUsage[]>;
+
 export function GetUserHomeDir():Promise<string>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function InstallClaudeVersion(arg1:string):Promise<void>;
+
+export function InstallPrivateNode():Promise<void>;
+
 export function LaunchClaude(arg1:boolean,arg2:string):Promise<void>;
 
+export function ListCaptureSessions():Promise<main.CaptureSession[]>;
+
+export function ListClaudeVersions():Promise<string[]>;
+
+export function LoadCaptureSession(arg1:string):Promise<main.CaptureRecord[]>;
+
 export function LoadConfig():Promise<main.AppConfig>;
 
+export function LoadRedactionLog(arg1:string):Promise<main.RedactionEvent[]>;
+
+export function MigrateClaudeToUserPrefix():Promise<void>;
+
 export function RecoverCC():Promise<void>;
 
+export function ResetUsage(arg1:string):Promise<void>;
+
 export function ResizeWindow(arg1:number,arg2:number):Promise<void>;
 
+export function RollbackClaude():Promise<string>;
+
 export function SaveConfig(arg1:main.AppConfig):Promise<void>;
 
 export function SelectProjectDir():Promise<string>;
@@ -2,14 +2,78 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CancelDownload() {
+  return window['go']['main']['App']['CancelDownload']();
+}
+
 export function CheckEnvironment() {
   return window['go']['main']['App']['CheckEnvironment']();
 }
 
+export function CheckKeys() {
+  return window['go']['main']['App']['CheckKeys']();
+}
+
+export function CheckOfflineBundle(arg1) {
+  return window['go']['main']['App']['CheckOfflineBundle'](arg1);
+}
+
 export function CheckUpdate(arg1) {
   return window['go']['main']['App']['CheckUpdate'](arg1);
 }
 
+export function CreateOfflineBundle(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateOfflineBundle'](arg1, arg2, arg3);
+}
+
+export function DeleteCaptureSession(arg1) {
+  return window['go']['main']['App']['DeleteCaptureSession'](arg1);
+}
+
+export function DetectFastestMirrors() {
+  return window['go']['main']['App']['DetectFastestMirrors']();
+}
+
+export function DiagnoseEnvironment() {
+  return window['go']['main']['App']['DiagnoseEnvironment']();
+}
+
+export function DiffCaptureRecords(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['DiffCaptureRecords'](arg1, arg2, arg3, arg4);
+}
+
+export function DiscoverLocalModels() {
+  return window['go']['main']['App']['DiscoverLocalModels']();
+}
+
+export function ExportDiagnostics() {
+  return window['go']['main']['App']['ExportDiagnostics']();
+}
+
+export function GetBalances() {
+  return window['go']['main']['App']['GetBalances']();
+}
+
+export function GetClaudeVersion() {
+  return window['go']['main']['App']['GetClaudeVersion']();
+}
+
+export function GetKeyHealth() {
+  return window['go']['main']['App']['GetKeyHealth']();
+}
+
+export function GetKeyStatus() {
+  return window['go']['main']['App']['GetKeyStatus']();
+}
+
+export function GetQueueStatus() {
+  return window['go']['main']['App']['GetQueueStatus']();
+}
+
+export function GetUsage() {
+  return window['go']['main']['App']['GetUsage']();
+}
+
 export function GetUserHomeDir() {
   return window['go']['main']['App']['GetUserHomeDir']();
 }
@@ -18,22 +82,58 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function InstallClaudeVersion(arg1) {
+  return window['go']['main']['App']['InstallClaudeVersion'](arg1);
+}
+
+export function InstallPrivateNode() {
+  return window['go']['main']['App']['InstallPrivateNode']();
+}
+
 export function LaunchClaude(arg1, arg2) {
   return window['go']['main']['App']['LaunchClaude'](arg1, arg2);
 }
 
+export function ListCaptureSessions() {
+  return window['go']['main']['App']['ListCaptureSessions']();
+}
+
+export function ListClaudeVersions() {
+  return window['go']['main']['App']['ListClaudeVersions']();
+}
+
+export function LoadCaptureSession(arg1) {
+  return window['go']['main']['App']['LoadCaptureSession'](arg1);
+}
+
 export function LoadConfig() {
   return window['go']['main']['App']['LoadConfig']();
 }
 
+export function LoadRedactionLog(arg1) {
+  return window['go']['main']['App']['LoadRedactionLog'](arg1);
+}
+
+export function MigrateClaudeToUserPrefix() {
+  return window['go']['main']['App']['MigrateClaudeToUserPrefix']();
+}
+
 export function RecoverCC() {
   return window['go']['main']['App']['RecoverCC']();
 }
 
+export function ResetUsage(arg1) {
+  return window['go']['main']['App']['ResetUsage'](arg1);
+}
+
 export function ResizeWindow(arg1, arg2) {
   return window['go']['main']['App']['ResizeWindow'](arg1, arg2);
 }
 
+export function RollbackClaude() {
+  return window['go']['main']['App']['RollbackClaude']();
+}
+
 export function SaveConfig(arg1) {
   return window['go']['main']['App']['SaveConfig'](arg1);
 }
@@ -1,10 +1,29 @@
 export namespace main {
 	
+	export class RedactionRule {
+	    name: string;
+	    detector: string;
+	    pattern: string;
+	    action: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RedactionRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.detector = source["detector"];
+	        this.pattern = source["pattern"];
+	        this.action = source["action"];
+	    }
+	}
 	export class ProjectConfig {
 	    id: string;
 	    name: string;
 	    path: string;
 	    yolo_mode: boolean;
+	    redactions: RedactionRule[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ProjectConfig(source);
@@ -16,13 +35,126 @@ export namespace main {
 	        this.name = source["name"];
 	        this.path = source["path"];
 	        this.yolo_mode = source["yolo_mode"];
+	        this.redactions = this.convertValues(source["redactions"], RedactionRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class BudgetConfig {
+	    daily_tokens: number;
+	    monthly_tokens: number;
+	    daily_cost: number;
+	    monthly_cost: number;
+	    input_price: number;
+	    output_price: number;
+	    warn_percent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BudgetConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.daily_tokens = source["daily_tokens"];
+	        this.monthly_tokens = source["monthly_tokens"];
+	        this.daily_cost = source["daily_cost"];
+	        this.monthly_cost = source["monthly_cost"];
+	        this.input_price = source["input_price"];
+	        this.output_price = source["output_price"];
+	        this.warn_percent = source["warn_percent"];
+	    }
+	}
+	export class CompatProfile {
+	    drop_headers: string[];
+	    remove_fields: string[];
+	    strip_cache_control: boolean;
+	    downgrade_thinking: boolean;
+	    emulate_count_tokens: boolean;
+	    token_factor: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompatProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.drop_headers = source["drop_headers"];
+	        this.remove_fields = source["remove_fields"];
+	        this.strip_cache_control = source["strip_cache_control"];
+	        this.downgrade_thinking = source["downgrade_thinking"];
+	        this.emulate_count_tokens = source["emulate_count_tokens"];
+	        this.token_factor = source["token_factor"];
+	    }
+	}
+	export class NetworkConfig {
+	    proxy: string;
+	    no_proxy: string;
+	    ca_bundle: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.proxy = source["proxy"];
+	        this.no_proxy = source["no_proxy"];
+	        this.ca_bundle = source["ca_bundle"];
+	    }
+	}
+	export class BalanceSource {
+	    url: string;
+	    field: string;
+	    currency: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BalanceSource(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.field = source["field"];
+	        this.currency = source["currency"];
 	    }
 	}
 	export class ModelConfig {
 	    model_name: string;
 	    model_url: string;
 	    api_key: string;
+	    api_keys: string[];
+	    model_id: string;
 	    is_custom: boolean;
+	    is_local: boolean;
+	    protocol: string;
+	    budget: BudgetConfig;
+	    max_concurrency: number;
+	    queue_timeout: number;
+	    temperature?: number;
+	    top_p?: number;
+	    max_tokens?: number;
+	    compat?: CompatProfile;
+	    network?: NetworkConfig;
+	    balance?: BalanceSource;
+	    balance_warn: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ModelConfig(source);
@@ -33,15 +165,181 @@ export namespace main {
 	        this.model_name = source["model_name"];
 	        this.model_url = source["model_url"];
 	        this.api_key = source["api_key"];
+	        this.api_keys = source["api_keys"];
+	        this.model_id = source["model_id"];
 	        this.is_custom = source["is_custom"];
+	        this.is_local = source["is_local"];
+	        this.protocol = source["protocol"];
+	        this.budget = this.convertValues(source["budget"], BudgetConfig);
+	        this.max_concurrency = source["max_concurrency"];
+	        this.queue_timeout = source["queue_timeout"];
+	        this.temperature = source["temperature"];
+	        this.top_p = source["top_p"];
+	        this.max_tokens = source["max_tokens"];
+	        this.compat = this.convertValues(source["compat"], CompatProfile);
+	        this.network = this.convertValues(source["network"], NetworkConfig);
+	        this.balance = this.convertValues(source["balance"], BalanceSource);
+	        this.balance_warn = source["balance_warn"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class KeyMonitorConfig {
+	    enabled: boolean;
+	    interval: number;
+	    billable_probe: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyMonitorConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.interval = source["interval"];
+	        this.billable_probe = source["billable_probe"];
+	    }
+	}
+	export class NodeConfig {
+	    version: string;
+	    min_version: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.min_version = source["min_version"];
+	    }
+	}
+	export class MirrorConfig {
+	    node: string;
+	    git: string;
+	    npm_registry: string;
+	    trust_mirror_checksums: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new MirrorConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.node = source["node"];
+	        this.git = source["git"];
+	        this.npm_registry = source["npm_registry"];
+	        this.trust_mirror_checksums = source["trust_mirror_checksums"];
+	    }
+	}
+	export class ClaudeCodeConfig {
+	    policy: string;
+	    version: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClaudeCodeConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.policy = source["policy"];
+	        this.version = source["version"];
 	    }
 	}
+	export class TierRoute {
+	    tier: string;
+	    model: string;
+	    target_model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TierRoute(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tier = source["tier"];
+	        this.model = source["model"];
+	        this.target_model = source["target_model"];
+	    }
+	}
+	export class GatewayConfig {
+	    enabled: boolean;
+	    port: number;
+	    capture: boolean;
+	    metrics: boolean;
+	    routes: TierRoute[];
+	    redactions: RedactionRule[];
+	    cassette_mode: string;
+	    cassette: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GatewayConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.port = source["port"];
+	        this.capture = source["capture"];
+	        this.metrics = source["metrics"];
+	        this.routes = this.convertValues(source["routes"], TierRoute);
+	        this.redactions = this.convertValues(source["redactions"], RedactionRule);
+	        this.cassette_mode = source["cassette_mode"];
+	        this.cassette = source["cassette"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
 	export class AppConfig {
 	    current_model: string;
 	    project_dir: string;
 	    models: ModelConfig[];
 	    projects: ProjectConfig[];
 	    current_project: string;
+	    network: NetworkConfig;
+	    key_monitor: KeyMonitorConfig;
+	    node: NodeConfig;
+	    mirrors: MirrorConfig;
+	    claude_code: ClaudeCodeConfig;
+	    offline_bundle: string;
+	    gateway: GatewayConfig;
+	    model_defaults_imported: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppConfig(source);
@@ -54,6 +352,14 @@ export namespace main {
 	        this.models = this.convertValues(source["models"], ModelConfig);
 	        this.projects = this.convertValues(source["projects"], ProjectConfig);
 	        this.current_project = source["current_project"];
+	        this.network = this.convertValues(source["network"], NetworkConfig);
+	        this.key_monitor = this.convertValues(source["key_monitor"], KeyMonitorConfig);
+	        this.node = this.convertValues(source["node"], NodeConfig);
+	        this.mirrors = this.convertValues(source["mirrors"], MirrorConfig);
+	        this.claude_code = this.convertValues(source["claude_code"], ClaudeCodeConfig);
+	        this.offline_bundle = source["offline_bundle"];
+	        this.gateway = this.convertValues(source["gateway"], GatewayConfig);
+	        this.model_defaults_imported = source["model_defaults_imported"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -90,6 +396,318 @@ export namespace main {
 	        this.latest_version = source["latest_version"];
 	    }
 	}
+	export class KeyHealth {
+	    model_name: string;
+	    key: string;
+	    key_id: string;
+	    status: string;
+	    message: string;
+	    checked_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyHealth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model_name = source["model_name"];
+	        this.key = source["key"];
+	        this.key_id = source["key_id"];
+	        this.status = source["status"];
+	        this.message = source["message"];
+	        this.checked_at = source["checked_at"];
+	    }
+	}
+	export class BundleFile {
+	    version: string;
+	    file: string;
+	    sha256: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BundleFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.file = source["file"];
+	        this.sha256 = source["sha256"];
+	    }
+	}
+	export class BundleManifest {
+	    format: number;
+	    created_at: string;
+	    os: string;
+	    arch: string;
+	    libc?: string;
+	    node: BundleFile;
+	    claude_code: BundleFile;
+	
+	    static createFrom(source: any = {}) {
+	        return new BundleManifest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.created_at = source["created_at"];
+	        this.os = source["os"];
+	        this.arch = source["arch"];
+	        this.libc = source["libc"];
+	        this.node = this.convertValues(source["node"], BundleFile);
+	        this.claude_code = this.convertValues(source["claude_code"], BundleFile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class DiagnosticItem {
+	    id: string;
+	    title: string;
+	    value: string;
+	    severity: string;
+	    fix?: string;
+	    fix_code?: string;
+	    fix_params?: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiagnosticItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.value = source["value"];
+	        this.severity = source["severity"];
+	        this.fix = source["fix"];
+	        this.fix_code = source["fix_code"];
+	        this.fix_params = source["fix_params"];
+	    }
+	}
+	export class DiagnosticReport {
+	    generated_at: string;
+	    os: string;
+	    arch: string;
+	    items: DiagnosticItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DiagnosticReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.generated_at = source["generated_at"];
+	        this.os = source["os"];
+	        this.arch = source["arch"];
+	        this.items = this.convertValues(source["items"], DiagnosticItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class Balance {
+	    model_name: string;
+	    amount: number;
+	    currency: string;
+	    low: boolean;
+	    error?: string;
+	    checked_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Balance(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model_name = source["model_name"];
+	        this.amount = source["amount"];
+	        this.currency = source["currency"];
+	        this.low = source["low"];
+	        this.error = source["error"];
+	        this.checked_at = source["checked_at"];
+	    }
+	}
+	export class KeyStatus {
+	    model_name: string;
+	    key: string;
+	    requests: number;
+	    failures: number;
+	    rate_limited: number;
+	    last_status: number;
+	    last_used: string;
+	    benched_until: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model_name = source["model_name"];
+	        this.key = source["key"];
+	        this.requests = source["requests"];
+	        this.failures = source["failures"];
+	        this.rate_limited = source["rate_limited"];
+	        this.last_status = source["last_status"];
+	        this.last_used = source["last_used"];
+	        this.benched_until = source["benched_until"];
+	    }
+	}
+	export class QueueStatus {
+	    model_name: string;
+	    max_concurrency: number;
+	    active: number;
+	    queued: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueueStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model_name = source["model_name"];
+	        this.max_concurrency = source["max_concurrency"];
+	        this.active = source["active"];
+	        this.queued = source["queued"];
+	    }
+	}
+	export class ModelUsage {
+	    model_name: string;
+	    day: string;
+	    day_tokens: number;
+	    day_cost: number;
+	    month: string;
+	    month_tokens: number;
+	    month_cost: number;
+	    warned: Record<string, boolean>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model_name = source["model_name"];
+	        this.day = source["day"];
+	        this.day_tokens = source["day_tokens"];
+	        this.day_cost = source["day_cost"];
+	        this.month = source["month"];
+	        this.month_tokens = source["month_tokens"];
+	        this.month_cost = source["month_cost"];
+	        this.warned = source["warned"];
+	    }
+	}
+	export class CaptureSession {
+	    id: string;
+	    records: number;
+	    size: number;
+	    mod_time: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CaptureSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.records = source["records"];
+	        this.size = source["size"];
+	        this.mod_time = source["mod_time"];
+	    }
+	}
+	export class CaptureRecord {
+	    id: string;
+	    time: string;
+	    session: string;
+	    provider: string;
+	    method: string;
+	    path: string;
+	    status: number;
+	    duration_ms: number;
+	    stream: boolean;
+	    request_headers: Record<string, string>;
+	    request: any;
+	    response_headers: Record<string, string>;
+	    response: any;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CaptureRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = source["time"];
+	        this.session = source["session"];
+	        this.provider = source["provider"];
+	        this.method = source["method"];
+	        this.path = source["path"];
+	        this.status = source["status"];
+	        this.duration_ms = source["duration_ms"];
+	        this.stream = source["stream"];
+	        this.request_headers = source["request_headers"];
+	        this.request = source["request"];
+	        this.response_headers = source["response_headers"];
+	        this.response = source["response"];
+	        this.error = source["error"];
+	    }
+	}
+	export class RedactionEvent {
+	    time: string;
+	    request_id: string;
+	    rule: string;
+	    action: string;
+	    matches: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RedactionEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.request_id = source["request_id"];
+	        this.rule = source["rule"];
+	        this.action = source["action"];
+	        this.matches = source["matches"];
+	    }
+	}
 
 }
 
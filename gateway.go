@@ -0,0 +1,618 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const defaultGatewayPort = 18765
+
+// GatewayConfig controls the optional local gateway that sits between
+// Claude Code and the selected provider.
+type GatewayConfig struct {
+	Enabled bool        `json:"enabled"`
+	Port    int         `json:"port"`
+	Capture bool        `json:"capture"` // Write every exchange to ~/.cceasy/captures
+	Metrics bool        `json:"metrics"` // Serve Prometheus metrics on /metrics
+	Routes  []TierRoute `json:"routes"`  // Send haiku/sonnet/opus requests to different models
+
+	Redactions []RedactionRule `json:"redactions"` // Applied to outgoing message content
+
+	CassetteMode string `json:"cassette_mode"` // "record", "replay" or empty
+	Cassette     string `json:"cassette"`      // Defaults to ~/.cceasy/cassettes/default.jsonl
+}
+
+func (c GatewayConfig) port() int {
+	if c.Port <= 0 {
+		return defaultGatewayPort
+	}
+	return c.Port
+}
+
+// gatewayURL is the base URL handed to Claude Code when the gateway is enabled.
+func gatewayURL(c GatewayConfig) string {
+	return fmt.Sprintf("http://127.0.0.1:%d", c.port())
+}
+
+// gatewayActive reports whether Claude Code has to go through the gateway,
+// either because it is enabled or because the selected model cannot be
+// reached directly. Budgets, key rotation, concurrency limits, sampling
+// overrides and redaction only apply to requests the gateway sees, so a
+// model with any of these, or any redaction rule, always sends traffic
+// through it.
+func gatewayActive(config AppConfig) bool {
+	if config.Gateway.Enabled || len(redactionRules(config)) > 0 {
+		return true
+	}
+	for _, m := range config.Models {
+		if m.ModelName == config.CurrentModel {
+			return m.isOpenAI() || m.Budget.isSet() || len(m.apiKeys()) > 1 ||
+				m.MaxConcurrency > 0 || m.hasSamplingOverrides()
+		}
+	}
+	return false
+}
+
+// gatewayAuthToken is the credential Claude Code is given while it talks to
+// the gateway. The gateway swaps it for the model's real key upstream, so
+// the real key stays out of settings.json and the shell environment.
+const gatewayAuthToken = "cceasy-gateway"
+
+// resolveBaseUrl returns the base URL Claude Code should talk to for the
+// selected model, taking the local gateway into account.
+func resolveBaseUrl(config AppConfig, selectedModel *ModelConfig) string {
+	if gatewayActive(config) {
+		return gatewayURL(config.Gateway)
+	}
+	return getBaseUrl(selectedModel)
+}
+
+// resolveAuthToken returns the credential Claude Code should send for the
+// selected model, taking the local gateway into account.
+func resolveAuthToken(config AppConfig, selectedModel *ModelConfig) string {
+	if gatewayActive(config) {
+		return gatewayAuthToken
+	}
+	return selectedModel.ApiKey
+}
+
+// Headers that must not be forwarded by a proxy (RFC 7230, section 6.1).
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+var sessionIdPattern = regexp.MustCompile(`session_([0-9A-Za-z-]+)`)
+
+// exchange holds everything the gateway learned about a single
+// request/response pair while proxying it.
+type exchange struct {
+	id         string
+	session    string
+	start      time.Time
+	model      ModelConfig
+	apiKey     string
+	method     string
+	path       string
+	reqHeader  http.Header
+	reqBody    []byte
+	status     int
+	respHeader http.Header
+	respBody   bytes.Buffer
+	stream     bool
+	events     []sseEvent
+	failovers  int
+	err        error
+}
+
+// Gateway is a reverse proxy on localhost that forwards Claude Code's
+// requests to the currently selected model's provider.
+type Gateway struct {
+	app    *App
+	client *http.Client
+
+	mu        sync.RWMutex
+	config    AppConfig
+	server    *http.Server
+	addr      string
+	sessionId string
+	seq       int64
+
+	capture    *captureWriter
+	budgets    *budgetLedger
+	keys       *keyPool
+	cassette   *Cassette
+	redactions *redactionLog
+	metrics    *gatewayMetrics
+	limiter    *concurrencyLimiter
+}
+
+func newGateway(app *App) *Gateway {
+	g := &Gateway{
+		app:        app,
+		sessionId:  time.Now().Format("20060102-150405"),
+		capture:    &captureWriter{},
+		budgets:    &budgetLedger{},
+		keys:       &keyPool{},
+		redactions: &redactionLog{},
+		metrics:    &gatewayMetrics{},
+		limiter:    &concurrencyLimiter{},
+	}
+	g.limiter.onChange = func() {
+		if app != nil && app.ctx != nil {
+			runtime.EventsEmit(app.ctx, "gateway-queue", g.limiter.snapshot(g.snapshot().Models))
+		}
+	}
+	g.client = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return g.transport(networkFromContext(req.Context())).RoundTrip(req)
+	})}
+	return g
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// transport returns the round tripper for upstream requests using the
+// given network settings, wrapping it in the cassette when recording or
+// replaying.
+func (g *Gateway) transport(n NetworkConfig) http.RoundTripper {
+	var base http.RoundTripper
+	t, err := sharedTransports.get(n)
+	if err != nil {
+		base = roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, err
+		})
+	} else {
+		base = t
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	mode := g.config.Gateway.CassetteMode
+	if g.cassette == nil || (mode != CassetteRecord && mode != CassetteReplay) {
+		return base
+	}
+	return &cassetteTransport{cassette: g.cassette, mode: mode, next: base}
+}
+
+// apply starts, stops or restarts the gateway so that it matches config.
+func (g *Gateway) apply(config AppConfig) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.config = config
+	if config.Gateway.CassetteMode != "" {
+		path := config.Gateway.Cassette
+		if path == "" {
+			path, _ = defaultCassettePath()
+		}
+		if g.cassette == nil || g.cassette.path != path {
+			g.cassette = openCassette(path)
+		}
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", config.Gateway.port())
+
+	active := gatewayActive(config)
+	if g.server != nil && (!active || g.addr != addr) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		g.server.Shutdown(ctx)
+		cancel()
+		g.server = nil
+		g.addr = ""
+	}
+
+	if !active || g.server != nil {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start gateway on %s: %w", addr, err)
+	}
+	g.server = &http.Server{Handler: g}
+	g.addr = addr
+	go g.server.Serve(ln)
+	return nil
+}
+
+func (g *Gateway) snapshot() AppConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.config
+}
+
+func (g *Gateway) nextId() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.seq++
+	return fmt.Sprintf("%s-%06d", time.Now().Format("150405"), g.seq)
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	config := g.snapshot()
+
+	if r.URL.Path == "/metrics" {
+		if !config.Gateway.Metrics {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		g.metrics.write(w)
+		return
+	}
+
+	start := time.Now()
+	var selectedModel *ModelConfig
+	for _, m := range config.Models {
+		if m.ModelName == config.CurrentModel {
+			selectedModel = &m
+			break
+		}
+	}
+	if selectedModel == nil {
+		g.reject(w, config, &exchange{start: start}, http.StatusBadGateway, "no_model", "api_error", "cceasy gateway: no model selected")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		g.reject(w, config, &exchange{start: start, model: *selectedModel}, http.StatusBadRequest, "bad_request", "invalid_request_error", "cceasy gateway: failed to read request body")
+		return
+	}
+
+	model, body := routeRequest(config, *selectedModel, body)
+	session := g.sessionFor(body)
+	ex := &exchange{
+		id:        g.nextId(),
+		session:   session,
+		start:     start,
+		model:     model,
+		method:    r.Method,
+		path:      r.URL.RequestURI(),
+		reqHeader: r.Header.Clone(),
+		reqBody:   body,
+	}
+
+	if isMessagesPath(r.URL.Path) || isCountTokensPath(r.URL.Path) {
+		rules, err := compileRedactionRules(redactionRules(config))
+		if err != nil {
+			// Fail closed rather than send content the user asked to protect
+			g.reject(w, config, ex, http.StatusBadRequest, "redaction_config", "invalid_request_error", "cceasy gateway: "+err.Error())
+			return
+		}
+		redacted, counts, blockedBy := redactRequest(rules, body)
+		if len(counts) > 0 {
+			if err := g.redactions.write(session, ex.id, rules, counts); err != nil && g.app != nil {
+				g.app.log("Failed to write redaction log: " + err.Error())
+			}
+		}
+		if blockedBy != "" {
+			g.reject(w, config, ex, http.StatusBadRequest, "redaction_block", "invalid_request_error", fmt.Sprintf("cceasy gateway: request blocked by redaction rule %q, nothing was sent. Edit the message with /rewind, or send another message to continue: text already in the conversation is masked instead of blocked.", blockedBy))
+			return
+		}
+		body = redacted
+	}
+
+	if isMessagesPath(r.URL.Path) {
+		body = applySamplingOverrides(model, body)
+		if err := g.budgets.check(model); err != nil {
+			// Anthropic reports exhausted credit as a 400, which Claude Code shows without retrying
+			g.reject(w, config, ex, http.StatusBadRequest, "budget", "invalid_request_error", err.Error())
+			return
+		}
+	}
+	ex.reqBody = body
+
+	release, err := g.limiter.acquire(r.Context(), model)
+	if err != nil {
+		w.Header().Set("Retry-After", "5")
+		g.reject(w, config, ex, http.StatusTooManyRequests, "queue", "rate_limit_error", "cceasy gateway: "+err.Error())
+		return
+	}
+	defer release()
+
+	sw := &statusWriter{ResponseWriter: w}
+	g.forward(sw, r, ex)
+	g.finish(config, ex, sw.status)
+}
+
+// statusWriter remembers the status sent to Claude Code, including errors
+// the gateway produced itself.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusWriter) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusWriter) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// sessionFor extracts the Claude Code session id from the request metadata,
+// falling back to one id per gateway run.
+func (g *Gateway) sessionFor(body []byte) string {
+	var req struct {
+		Metadata struct {
+			UserId string `json:"user_id"`
+		} `json:"metadata"`
+	}
+	if json.Unmarshal(body, &req) == nil {
+		if m := sessionIdPattern.FindStringSubmatch(req.Metadata.UserId); len(m) == 2 {
+			return m[1]
+		}
+	}
+	return g.sessionId
+}
+
+func (g *Gateway) forward(w http.ResponseWriter, r *http.Request, ex *exchange) {
+	// OpenAI-protocol providers have no equivalent endpoint at all
+	if isCountTokensPath(r.URL.Path) && (ex.model.isOpenAI() || ex.model.compatProfile().EmulateCountTokens) {
+		g.countTokensLocally(w, ex)
+		return
+	}
+	if ex.model.isOpenAI() {
+		g.forwardOpenAI(w, r, ex)
+		return
+	}
+
+	upstream := strings.TrimRight(getBaseUrl(&ex.model), "/") + r.URL.Path
+	if r.URL.RawQuery != "" {
+		upstream += "?" + r.URL.RawQuery
+	}
+
+	compat := ex.model.compatProfile()
+	ex.reqBody = compat.applyBody(ex.reqBody)
+
+	resp := g.roundTrip(w, r, ex, upstream, ex.reqBody, func(h http.Header, key string) {
+		for k, vv := range r.Header {
+			h[k] = append([]string(nil), vv...)
+		}
+		removeHopHeaders(h)
+		compat.applyHeaders(h)
+		// Let the transport negotiate compression so the body can be inspected
+		h.Del("Accept-Encoding")
+		h.Del("Content-Length")
+		setUpstreamAuth(h, key)
+	})
+	if resp == nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	ex.status = resp.StatusCode
+	ex.respHeader = resp.Header.Clone()
+	ex.stream = strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+
+	copyResponseHeader(w, resp.Header)
+	w.WriteHeader(resp.StatusCode)
+
+	var decoder *sseDecoder
+	if ex.stream {
+		decoder = &sseDecoder{onEvent: func(ev sseEvent) {
+			ex.events = append(ex.events, ev)
+		}}
+	}
+
+	flusher, _ := w.(http.Flusher)
+	buffer := make([]byte, 32768)
+	for {
+		n, err := resp.Body.Read(buffer)
+		if n > 0 {
+			chunk := buffer[:n]
+			if _, werr := w.Write(chunk); werr != nil {
+				ex.err = werr
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			if decoder != nil {
+				decoder.Write(chunk)
+			} else {
+				ex.respBody.Write(chunk)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			ex.err = err
+			break
+		}
+	}
+	if decoder != nil {
+		decoder.Close()
+	}
+}
+
+// roundTrip sends body upstream, rotating through the model's keys while
+// they are rate limited. prepare fills in the headers for the chosen key.
+// On failure an error has already been written to w and nil is returned.
+func (g *Gateway) roundTrip(w http.ResponseWriter, r *http.Request, ex *exchange, upstream string, body []byte, prepare func(h http.Header, key string)) *http.Response {
+	if g.replaying() {
+		// Recorded responses need no credentials, so replay works offline
+		// without an API key
+		resp, err := g.send(r, ex, upstream, body, prepare, "")
+		if err != nil {
+			ex.err = err
+			writeGatewayError(w, http.StatusBadGateway, "api_error", "cceasy gateway: "+err.Error())
+			return nil
+		}
+		return resp
+	}
+
+	tried := make(map[string]bool)
+	var resp *http.Response
+	for {
+		key, wait, err := g.keys.pick(ex.model, tried)
+		if err != nil {
+			if resp != nil {
+				// Out of keys; hand the last rate limit response to Claude Code
+				return resp
+			}
+			ex.err = err
+			if wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+				writeGatewayError(w, http.StatusTooManyRequests, "rate_limit_error", "cceasy gateway: "+err.Error())
+			} else {
+				writeGatewayError(w, http.StatusUnauthorized, "authentication_error", "cceasy gateway: "+err.Error())
+			}
+			return nil
+		}
+		if resp != nil {
+			resp.Body.Close()
+			ex.failovers++
+		}
+		tried[key] = true
+		ex.apiKey = key
+
+		resp, err = g.send(r, ex, upstream, body, prepare, key)
+		if err != nil {
+			ex.err = err
+			writeGatewayError(w, http.StatusBadGateway, "api_error", "cceasy gateway: upstream request failed: "+err.Error())
+			return nil
+		}
+		g.keys.report(ex.model.ModelName, key, resp.StatusCode, resp.Header)
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp
+		}
+	}
+}
+
+// send issues a single upstream request authenticated with key.
+func (g *Gateway) send(r *http.Request, ex *exchange, upstream string, body []byte, prepare func(h http.Header, key string), key string) (*http.Response, error) {
+	ctx := withNetwork(r.Context(), effectiveNetwork(g.snapshot().Network, &ex.model))
+	req, err := http.NewRequestWithContext(ctx, r.Method, upstream, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	prepare(req.Header, key)
+	return g.client.Do(req)
+}
+
+// replaying reports whether upstream requests are answered from the cassette.
+func (g *Gateway) replaying() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.cassette != nil && g.config.Gateway.CassetteMode == CassetteReplay
+}
+
+func copyResponseHeader(w http.ResponseWriter, h http.Header) {
+	for k, vv := range h {
+		for _, v := range vv {
+			w.Header().Add(k, v)
+		}
+	}
+	removeHopHeaders(w.Header())
+	w.Header().Del("Content-Length")
+}
+
+// reject answers a request the gateway refuses to forward and counts it
+// under class in the metrics.
+func (g *Gateway) reject(w http.ResponseWriter, config AppConfig, ex *exchange, status int, class, errType, message string) {
+	writeGatewayError(w, status, errType, message)
+	if config.Gateway.Metrics {
+		g.metrics.reject(exchangeLabels(config, ex), status, class, time.Since(ex.start))
+	}
+}
+
+// finish runs the post-response bookkeeping for an exchange.
+func (g *Gateway) finish(config AppConfig, ex *exchange, status int) {
+	if config.Gateway.Capture {
+		if err := g.capture.write(ex); err != nil && g.app != nil {
+			g.app.log("Gateway capture failed: " + err.Error())
+		}
+	}
+
+	usage, ok := exchangeUsage(ex)
+	if config.Gateway.Metrics {
+		g.metrics.observe(exchangeLabels(config, ex), status, ex.err, time.Since(ex.start), usage, ex.failovers)
+	}
+	if ok {
+		warnings, err := g.budgets.record(ex.model, usage)
+		if err != nil && g.app != nil {
+			g.app.log("Failed to save usage: " + err.Error())
+		}
+		for _, w := range warnings {
+			if g.app != nil {
+				g.app.notify("Budget warning", w)
+			}
+		}
+	}
+}
+
+func isMessagesPath(path string) bool {
+	return strings.HasSuffix(strings.TrimRight(path, "/"), "/v1/messages")
+}
+
+func isCountTokensPath(path string) bool {
+	return strings.HasSuffix(strings.TrimRight(path, "/"), "/v1/messages/count_tokens")
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+// setUpstreamAuth replaces whatever credentials Claude Code sent with the
+// key configured for the upstream provider.
+func setUpstreamAuth(h http.Header, apiKey string) {
+	if h.Get("X-Api-Key") != "" {
+		h.Set("X-Api-Key", apiKey)
+	}
+	if h.Get("Authorization") != "" || h.Get("X-Api-Key") == "" {
+		h.Set("Authorization", "Bearer "+apiKey)
+	}
+}
+
+// writeGatewayError replies with an error body shaped like the Anthropic API,
+// so Claude Code surfaces the message instead of a parse failure.
+func writeGatewayError(w http.ResponseWriter, status int, errType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type": "error",
+		"error": map[string]string{
+			"type":    errType,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Used when the key monitor is enabled without an interval.
+const defaultKeyCheckInterval = 60
+
+const (
+	KeyHealthOk          = "ok"
+	KeyHealthInvalid     = "invalid"
+	KeyHealthNoCredit    = "no_credit"
+	KeyHealthRateLimited = "rate_limited"
+	KeyHealthError       = "error"
+	KeyHealthUnchecked   = "unchecked" // No free way to check the key
+)
+
+// KeyMonitorConfig controls the background job that validates API keys.
+type KeyMonitorConfig struct {
+	Enabled  bool `json:"enabled"`
+	Interval int  `json:"interval"` // Minutes between checks
+	// Send a one-token message when the provider has no free endpoint to
+	// check a key against. Each check is billed.
+	BillableProbe bool `json:"billable_probe"`
+}
+
+// KeyHealth is the last check result for one API key. The key is masked.
+type KeyHealth struct {
+	ModelName string `json:"model_name"`
+	Key       string `json:"key"`
+	KeyId     string `json:"key_id"` // Hash prefix telling keys apart without storing them
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	CheckedAt string `json:"checked_at"`
+}
+
+func (h KeyHealth) failed() bool {
+	return h.Status == KeyHealthInvalid || h.Status == KeyHealthNoCredit
+}
+
+var (
+	creditErrorPattern = regexp.MustCompile(`(?i)balance|quota|credit|insufficient|billing|余额|欠费|额度`)
+	authErrorPattern   = regexp.MustCompile(`(?i)api[ _-]?key|invalid.{0,20}token|token.{0,20}(invalid|expired)|unauthori[sz]ed|authenticat|密钥|令牌|鉴权|认证`)
+	// Sample values such as "your_minimax_api_key_here" written by older versions
+	placeholderKeyPattern = regexp.MustCompile(`(?i)^your_.*_here$`)
+)
+
+// isPlaceholderKey reports keys that were never filled in.
+func isPlaceholderKey(key string) bool {
+	key = strings.TrimSpace(key)
+	return key == "" || placeholderKeyPattern.MatchString(key)
+}
+
+func keyId(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
+
+// checkKey validates a key with calls that are not billed: the model list,
+// then count_tokens for Anthropic-protocol providers that lack one. A
+// one-token message is only sent when billable is set; otherwise a
+// provider with neither endpoint leaves the key unchecked.
+func checkKey(ctx context.Context, client *http.Client, m ModelConfig, key string, billable bool) KeyHealth {
+	result := KeyHealth{
+		ModelName: m.ModelName,
+		Key:       maskKey(key),
+		KeyId:     keyId(key),
+		CheckedAt: time.Now().Format(time.RFC3339),
+	}
+
+	base := strings.TrimRight(getBaseUrl(&m), "/")
+	probes := []func() (*http.Request, error){}
+	if m.isOpenAI() {
+		probes = append(probes, func() (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, base+"/models", nil)
+		})
+	} else {
+		messages := []map[string]string{{"role": "user", "content": "hi"}}
+		probes = append(probes, func() (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, base+"/v1/models", nil)
+		}, func() (*http.Request, error) {
+			body, _ := json.Marshal(map[string]interface{}{"model": getModelId(&m), "messages": messages})
+			return http.NewRequestWithContext(ctx, http.MethodPost, base+"/v1/messages/count_tokens", bytes.NewReader(body))
+		})
+		if billable {
+			probes = append(probes, func() (*http.Request, error) {
+				body, _ := json.Marshal(map[string]interface{}{"model": getModelId(&m), "max_tokens": 1, "messages": messages})
+				return http.NewRequestWithContext(ctx, http.MethodPost, base+"/v1/messages", bytes.NewReader(body))
+			})
+		}
+	}
+
+	for _, probe := range probes {
+		req, err := probe()
+		if err != nil {
+			result.Status = KeyHealthError
+			result.Message = err.Error()
+			return result
+		}
+		req.Header.Set("Authorization", "Bearer "+key)
+		if !m.isOpenAI() {
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("anthropic-version", "2023-06-01")
+			req.Header.Set("X-Api-Key", key)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			result.Status = KeyHealthError
+			result.Message = err.Error()
+			return result
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+		// The provider does not offer this endpoint; try the next one
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+			continue
+		}
+		result.Status, result.Message = classifyKeyCheck(resp.StatusCode, data)
+		return result
+	}
+
+	result.Status = KeyHealthUnchecked
+	result.Message = "the provider has no free endpoint to check keys against; set key_monitor.billable_probe to send a one-token message"
+	return result
+}
+
+// classifyKeyCheck maps a provider's answer to a key health status. Some
+// providers reject bad keys with a 400, so a 400 only counts as a problem
+// with the key when its message says so.
+func classifyKeyCheck(status int, body []byte) (string, string) {
+	message := http.StatusText(status)
+	var e struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &e) == nil {
+		if e.Error.Message != "" {
+			message = e.Error.Message
+		} else if e.Message != "" {
+			message = e.Message
+		}
+	}
+
+	switch {
+	case status < 300:
+		return KeyHealthOk, ""
+	case status == http.StatusPaymentRequired:
+		return KeyHealthNoCredit, message
+	case (status == http.StatusBadRequest || status == http.StatusTooManyRequests || status == http.StatusForbidden) && creditErrorPattern.MatchString(message):
+		return KeyHealthNoCredit, message
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return KeyHealthInvalid, message
+	case status == http.StatusBadRequest && authErrorPattern.MatchString(message):
+		return KeyHealthInvalid, message
+	case status == http.StatusTooManyRequests:
+		return KeyHealthRateLimited, message
+	}
+	return KeyHealthError, message
+}
+
+// keyHealthBadge prefixes a model's tray entry with the colour of its
+// worst key status.
+func keyHealthBadge(status string) string {
+	switch status {
+	case KeyHealthOk:
+		return "🟢 "
+	case KeyHealthRateLimited, KeyHealthError:
+		return "🟡 "
+	case KeyHealthInvalid, KeyHealthNoCredit:
+		return "🔴 "
+	}
+	return ""
+}
+
+// modelKeyStatus reduces per-key results to the worst status per model.
+func modelKeyStatus(results []KeyHealth) map[string]string {
+	rank := map[string]int{KeyHealthOk: 1, KeyHealthRateLimited: 2, KeyHealthError: 3, KeyHealthNoCredit: 4, KeyHealthInvalid: 5}
+	status := make(map[string]string)
+	for _, r := range results {
+		if rank[r.Status] > rank[status[r.ModelName]] {
+			status[r.ModelName] = r.Status
+		}
+	}
+	return status
+}
+
+func getKeyHealthPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cceasy", "key_health.json"), nil
+}
+
+var keyHealthMu sync.Mutex
+
+// GetKeyHealth returns the results of the last key check.
+func (a *App) GetKeyHealth() ([]KeyHealth, error) {
+	path, err := getKeyHealthPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []KeyHealth{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := []KeyHealth{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CheckKeys validates every configured key now, stores the results and
+// raises a notification for each key that has newly failed.
+func (a *App) CheckKeys() ([]KeyHealth, error) {
+	keyHealthMu.Lock()
+	defer keyHealthMu.Unlock()
+
+	config, err := a.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	previous, _ := a.GetKeyHealth()
+	before := make(map[string]KeyHealth)
+	for _, h := range previous {
+		before[h.ModelName+"/"+h.KeyId] = h
+	}
+
+	results := []KeyHealth{}
+	for _, m := range config.Models {
+		if m.IsLocal || getBaseUrl(&m) == "" {
+			continue
+		}
+		transport, err := sharedTransports.get(effectiveNetwork(config.Network, &m))
+		if err != nil {
+			a.log("Key check skipped for " + m.ModelName + ": " + err.Error())
+			continue
+		}
+		client := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+		for _, key := range m.apiKeys() {
+			if isPlaceholderKey(key) {
+				continue
+			}
+			h := checkKey(context.Background(), client, m, key, config.KeyMonitor.BillableProbe)
+			results = append(results, h)
+			if h.failed() && !before[h.ModelName+"/"+h.KeyId].failed() {
+				a.notify("API key problem", fmt.Sprintf("%s key %s: %s", h.ModelName, h.Key, h.Message))
+			}
+		}
+	}
+
+	path, err := getKeyHealthPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, err
+	}
+
+	a.publishKeyHealth(results)
+	return results, nil
+}
+
+// publishKeyHealth pushes results to the frontend and the tray.
+func (a *App) publishKeyHealth(results []KeyHealth) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "key-health", results)
+	}
+	if OnKeyHealthChanged != nil {
+		OnKeyHealthChanged(modelKeyStatus(results))
+	}
+}
+
+// runKeyMonitor re-checks keys in the background whenever the configured
+// interval has passed since the last check.
+func (a *App) runKeyMonitor() {
+	var last time.Time
+	if results, err := a.GetKeyHealth(); err == nil && len(results) > 0 {
+		last, _ = time.Parse(time.RFC3339, results[0].CheckedAt)
+	}
+	for {
+		config, _ := a.LoadConfig()
+		interval := config.KeyMonitor.Interval
+		if interval <= 0 {
+			interval = defaultKeyCheckInterval
+		}
+		if config.KeyMonitor.Enabled && time.Since(last) >= time.Duration(interval)*time.Minute {
+			if _, err := a.CheckKeys(); err != nil {
+				a.log("Key check failed: " + err.Error())
+			}
+			last = time.Now()
+		}
+		time.Sleep(time.Minute)
+	}
+}
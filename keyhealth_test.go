@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckKeyUsesFreeEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   map[string]int // path -> status; anything else is a 404
+		billable bool
+		want     string
+		wantHits []string
+	}{
+		{"model list", map[string]int{"/v1/models": 200}, false, KeyHealthOk, []string{"/v1/models"}},
+		{"count_tokens", map[string]int{"/v1/messages/count_tokens": 401}, false, KeyHealthInvalid, []string{"/v1/models", "/v1/messages/count_tokens"}},
+		{"no free endpoint", map[string]int{"/v1/messages": 200}, false, KeyHealthUnchecked, []string{"/v1/models", "/v1/messages/count_tokens"}},
+		{"billable opt-in", map[string]int{"/v1/messages": 200}, true, KeyHealthOk, []string{"/v1/models", "/v1/messages/count_tokens", "/v1/messages"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits = append(hits, r.URL.Path)
+				if r.Header.Get("X-Api-Key") != "sk-test" {
+					t.Errorf("%s sent without the key", r.URL.Path)
+				}
+				status, ok := tt.routes[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			m := ModelConfig{ModelName: "Custom", ModelUrl: srv.URL, ModelId: "m", IsCustom: true}
+			got := checkKey(context.Background(), srv.Client(), m, "sk-test", tt.billable)
+			if got.Status != tt.want {
+				t.Errorf("status = %q (%s), want %q", got.Status, got.Message, tt.want)
+			}
+			if len(hits) != len(tt.wantHits) {
+				t.Fatalf("requests = %v, want %v", hits, tt.wantHits)
+			}
+			for i := range hits {
+				if hits[i] != tt.wantHits[i] {
+					t.Fatalf("requests = %v, want %v", hits, tt.wantHits)
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyKeyCheck(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{200, `{}`, KeyHealthOk},
+		{401, `{"error":{"message":"invalid x-api-key"}}`, KeyHealthInvalid},
+		{402, `{}`, KeyHealthNoCredit},
+		{429, `{"error":{"message":"Rate limit reached"}}`, KeyHealthRateLimited},
+		{429, `{"error":{"message":"余额不足或无可用资源包"}}`, KeyHealthNoCredit},
+		{400, `{"error":{"message":"Your credit balance is too low"}}`, KeyHealthNoCredit},
+		{400, `{"error":{"message":"API key not valid. Please pass a valid API key."}}`, KeyHealthInvalid},
+		{400, `{"error":{"message":"令牌已过期或验证不正确"}}`, KeyHealthInvalid},
+		// A 400 that says nothing about the key is not proof it works
+		{400, `{"error":{"message":"max_tokens: Field required"}}`, KeyHealthError},
+		{400, `{"error":{"message":"model not found"}}`, KeyHealthError},
+		{500, `{}`, KeyHealthError},
+	}
+	for _, tt := range tests {
+		if got, _ := classifyKeyCheck(tt.status, []byte(tt.body)); got != tt.want {
+			t.Errorf("classifyKeyCheck(%d, %s) = %q, want %q", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestIsPlaceholderKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"":                          true,
+		"  ":                        true,
+		"your_minimax_api_key_here": true,
+		"YOUR_GLM_API_KEY_HERE":     true,
+		"sk-ant-api03-abc":          false,
+		"your_key":                  false,
+	} {
+		if got := isPlaceholderKey(key); got != want {
+			t.Errorf("isPlaceholderKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Used when a 429 response carries no usable retry-after header.
+const defaultKeyBenchDuration = 60 * time.Second
+
+// apiKeys returns the model's key pool: the primary key followed by any
+// extra keys, without blanks or duplicates.
+func (m ModelConfig) apiKeys() []string {
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, k := range append([]string{m.ApiKey}, m.ApiKeys...) {
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// KeyStatus reports the health and usage of one pooled key. The key itself
+// is masked.
+type KeyStatus struct {
+	ModelName    string `json:"model_name"`
+	Key          string `json:"key"`
+	Requests     int64  `json:"requests"`
+	Failures     int64  `json:"failures"`
+	RateLimited  int64  `json:"rate_limited"`
+	LastStatus   int    `json:"last_status"`
+	LastUsed     string `json:"last_used"`
+	BenchedUntil string `json:"benched_until"`
+}
+
+type keyState struct {
+	requests     int64
+	failures     int64
+	rateLimited  int64
+	lastStatus   int
+	lastUsed     time.Time
+	benchedUntil time.Time
+}
+
+// keyPool hands out keys round-robin and benches keys that were rate limited.
+type keyPool struct {
+	mu     sync.Mutex
+	next   map[string]int
+	states map[string]map[string]*keyState
+}
+
+func (p *keyPool) state(modelName, key string) *keyState {
+	if p.states == nil {
+		p.states = make(map[string]map[string]*keyState)
+		p.next = make(map[string]int)
+	}
+	if p.states[modelName] == nil {
+		p.states[modelName] = make(map[string]*keyState)
+	}
+	s, ok := p.states[modelName][key]
+	if !ok {
+		s = &keyState{}
+		p.states[modelName][key] = s
+	}
+	return s
+}
+
+// pick returns the next key that is not benched, skipping any in exclude.
+// When every key is benched it returns how long until the first one frees up.
+func (p *keyPool) pick(model ModelConfig, exclude map[string]bool) (string, time.Duration, error) {
+	keys := model.apiKeys()
+	if len(keys) == 0 {
+		return "", 0, fmt.Errorf("no API key configured for %s", model.ModelName)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	start := p.next[model.ModelName]
+	for i := 0; i < len(keys); i++ {
+		idx := (start + i) % len(keys)
+		key := keys[idx]
+		if exclude[key] {
+			continue
+		}
+		s := p.state(model.ModelName, key)
+		if now.Before(s.benchedUntil) {
+			if d := s.benchedUntil.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		p.next[model.ModelName] = idx + 1
+		s.requests++
+		s.lastUsed = now
+		return key, 0, nil
+	}
+	if wait == 0 {
+		return "", 0, fmt.Errorf("all API keys for %s failed", model.ModelName)
+	}
+	return "", wait, fmt.Errorf("all API keys for %s are rate limited", model.ModelName)
+}
+
+// report records the outcome of a request made with key. A 429 benches the
+// key for as long as the provider asked.
+func (p *keyPool) report(modelName, key string, status int, header http.Header) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.state(modelName, key)
+	s.lastStatus = status
+	if status >= 400 {
+		s.failures++
+	}
+	if status == http.StatusTooManyRequests {
+		s.rateLimited++
+		s.benchedUntil = time.Now().Add(retryAfter(header))
+	}
+}
+
+func (p *keyPool) snapshot(models []ModelConfig) []KeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := []KeyStatus{}
+	for _, m := range models {
+		for _, key := range m.apiKeys() {
+			s := p.state(m.ModelName, key)
+			status := KeyStatus{
+				ModelName:   m.ModelName,
+				Key:         maskKey(key),
+				Requests:    s.requests,
+				Failures:    s.failures,
+				RateLimited: s.rateLimited,
+				LastStatus:  s.lastStatus,
+			}
+			if !s.lastUsed.IsZero() {
+				status.LastUsed = s.lastUsed.Format(time.RFC3339)
+			}
+			if time.Now().Before(s.benchedUntil) {
+				status.BenchedUntil = s.benchedUntil.Format(time.RFC3339)
+			}
+			result = append(result, status)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ModelName < result[j].ModelName
+	})
+	return result
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return defaultKeyBenchDuration
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return defaultKeyBenchDuration
+}
+
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:3] + "..." + key[len(key)-4:]
+}
+
+// GetKeyStatus returns per-key health and usage for every model's key pool.
+func (a *App) GetKeyStatus() []KeyStatus {
+	config, _ := a.LoadConfig()
+	return a.gateway.keys.snapshot(config.Models)
+}
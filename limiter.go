@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Used when a model limits concurrency without setting a queue timeout.
+const defaultQueueTimeout = 120 * time.Second
+
+// QueueStatus reports how many requests a model has in flight and waiting.
+type QueueStatus struct {
+	ModelName      string `json:"model_name"`
+	MaxConcurrency int    `json:"max_concurrency"`
+	Active         int    `json:"active"`
+	Queued         int    `json:"queued"`
+}
+
+type modelQueue struct {
+	max     int
+	active  int
+	waiters []chan struct{}
+}
+
+// concurrencyLimiter caps in-flight requests per model. Requests over the
+// limit wait in FIFO order until a slot frees up or their timeout expires.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	queues   map[string]*modelQueue
+	onChange func()
+}
+
+func (l *concurrencyLimiter) queue(modelName string) *modelQueue {
+	if l.queues == nil {
+		l.queues = make(map[string]*modelQueue)
+	}
+	q, ok := l.queues[modelName]
+	if !ok {
+		q = &modelQueue{}
+		l.queues[modelName] = q
+	}
+	return q
+}
+
+// acquire waits for a slot for model and returns the function that frees
+// it. Models without a limit are never queued.
+func (l *concurrencyLimiter) acquire(ctx context.Context, model ModelConfig) (func(), error) {
+	if model.MaxConcurrency <= 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	q := l.queue(model.ModelName)
+	q.max = model.MaxConcurrency
+	if q.active < q.max && len(q.waiters) == 0 {
+		q.active++
+		l.mu.Unlock()
+		l.changed()
+		return l.releaser(model.ModelName), nil
+	}
+	ready := make(chan struct{})
+	q.waiters = append(q.waiters, ready)
+	l.mu.Unlock()
+	l.changed()
+
+	timeout := time.Duration(model.QueueTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultQueueTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-ready:
+		return l.releaser(model.ModelName), nil
+	case <-timer.C:
+		err = fmt.Errorf("timed out after %s waiting for a free slot on %s", timeout, model.ModelName)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	granted := true
+	for i, w := range q.waiters {
+		if w == ready {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			granted = false
+			break
+		}
+	}
+	l.mu.Unlock()
+	if granted {
+		// The slot was handed over just as we gave up; pass it on
+		l.releaser(model.ModelName)()
+	} else {
+		l.changed()
+	}
+	return nil, err
+}
+
+func (l *concurrencyLimiter) releaser(modelName string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			q := l.queue(modelName)
+			q.active--
+			for q.active < q.max && len(q.waiters) > 0 {
+				q.active++
+				close(q.waiters[0])
+				q.waiters = q.waiters[1:]
+			}
+			l.mu.Unlock()
+			l.changed()
+		})
+	}
+}
+
+func (l *concurrencyLimiter) changed() {
+	if l.onChange != nil {
+		l.onChange()
+	}
+}
+
+func (l *concurrencyLimiter) snapshot(models []ModelConfig) []QueueStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := []QueueStatus{}
+	for _, m := range models {
+		if m.MaxConcurrency <= 0 {
+			continue
+		}
+		q := l.queue(m.ModelName)
+		result = append(result, QueueStatus{
+			ModelName:      m.ModelName,
+			MaxConcurrency: m.MaxConcurrency,
+			Active:         q.active,
+			Queued:         len(q.waiters),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ModelName < result[j].ModelName
+	})
+	return result
+}
+
+// GetQueueStatus returns in-flight and queued request counts for every
+// model with a concurrency limit. The frontend is also sent a
+// "gateway-queue" event whenever they change.
+func (a *App) GetQueueStatus() []QueueStatus {
+	config, _ := a.LoadConfig()
+	return a.gateway.limiter.snapshot(config.Models)
+}
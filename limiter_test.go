@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestConcurrencyLimitActivatesGateway(t *testing.T) {
+	config := AppConfig{
+		CurrentModel: "GLM",
+		Models:       []ModelConfig{{ModelName: "GLM", ApiKey: "k"}, {ModelName: "kimi", ApiKey: "k", MaxConcurrency: 2}},
+	}
+	if gatewayActive(config) {
+		t.Fatal("gateway active for an unlimited model")
+	}
+	config.CurrentModel = "kimi"
+	if !gatewayActive(config) {
+		t.Error("a model with max_concurrency bypasses the gateway")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Placeholder key for local servers; they ignore it, but Claude Code
+// refuses to start without one.
+const localApiKey = "local"
+
+// localCandidate is a well-known local inference server to look for.
+type localCandidate struct {
+	Name string
+	Url  string
+}
+
+var localCandidates = []localCandidate{
+	{Name: "Ollama", Url: "http://127.0.0.1:11434"},
+	{Name: "LM Studio", Url: "http://127.0.0.1:1234"},
+	{Name: "llama.cpp", Url: "http://127.0.0.1:8080"},
+}
+
+// LocalServer is a local inference server found by discovery.
+type LocalServer struct {
+	Name     string   `json:"name"`
+	Url      string   `json:"url"`
+	Protocol string   `json:"protocol"`
+	Models   []string `json:"models"`
+}
+
+// probeLocalServers checks each candidate for an OpenAI-compatible model
+// list and, where offered, a native Anthropic Messages endpoint.
+// Unreachable candidates are left out.
+func probeLocalServers(ctx context.Context, client *http.Client, candidates []localCandidate) []LocalServer {
+	servers := []LocalServer{}
+	for _, c := range candidates {
+		base := strings.TrimRight(c.Url, "/")
+		models, err := listLocalModels(ctx, client, base)
+		if err != nil {
+			continue
+		}
+		server := LocalServer{Name: c.Name, Url: base + "/v1", Protocol: ProtocolOpenAI, Models: models}
+		if supportsAnthropicMessages(ctx, client, base) {
+			server.Url = base
+			server.Protocol = ProtocolAnthropic
+		}
+		servers = append(servers, server)
+	}
+	return servers
+}
+
+func listLocalModels(ctx context.Context, client *http.Client, base string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/v1/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s/v1/models returned %s", base, resp.Status)
+	}
+
+	var list struct {
+		Data []struct {
+			Id string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	models := []string{}
+	for _, m := range list.Data {
+		if m.Id != "" {
+			models = append(models, m.Id)
+		}
+	}
+	return models, nil
+}
+
+// supportsAnthropicMessages sends an empty Messages request; a server that
+// implements the endpoint rejects it as invalid instead of answering 404.
+func supportsAnthropicMessages(ctx context.Context, client *http.Client, base string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/v1/messages", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", "2023-06-01")
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity
+}
+
+// localModelConfigs turns discovered servers into model entries.
+func localModelConfigs(servers []LocalServer) []ModelConfig {
+	models := []ModelConfig{}
+	for _, s := range servers {
+		for _, id := range s.Models {
+			models = append(models, ModelConfig{
+				ModelName: fmt.Sprintf("%s (%s)", id, s.Name),
+				ModelUrl:  s.Url,
+				ModelId:   id,
+				ApiKey:    localApiKey,
+				Protocol:  s.Protocol,
+				IsLocal:   true,
+			})
+		}
+	}
+	return models
+}
+
+// DiscoverLocalModels looks for Ollama, LM Studio and llama.cpp on their
+// default ports and adds a model entry for every model they serve that is
+// not configured yet. It returns the added entries.
+func (a *App) DiscoverLocalModels() ([]ModelConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client := &http.Client{Timeout: 3 * time.Second}
+	servers := probeLocalServers(ctx, client, localCandidates)
+
+	config, err := a.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, m := range config.Models {
+		existing[m.ModelName] = true
+	}
+
+	added := []ModelConfig{}
+	for _, m := range localModelConfigs(servers) {
+		if !existing[m.ModelName] {
+			added = append(added, m)
+		}
+	}
+	if len(added) == 0 {
+		return added, nil
+	}
+
+	// Keep Custom as the last entry
+	models := []ModelConfig{}
+	for _, m := range config.Models {
+		if m.IsCustom {
+			models = append(models, added...)
+		}
+		models = append(models, m)
+	}
+	if len(models) == len(config.Models) {
+		models = append(models, added...)
+	}
+	config.Models = models
+	if err := a.SaveConfig(config); err != nil {
+		return nil, err
+	}
+	a.log(fmt.Sprintf("Added %d local models", len(added)))
+	return added, nil
+}
+
+// localModelHealth reports how many of the servers behind the configured
+// local models respond.
+func localModelHealth(ctx context.Context, client *http.Client, models []ModelConfig) (online, total int) {
+	seen := make(map[string]bool)
+	for _, m := range models {
+		if !m.IsLocal || seen[m.ModelUrl] {
+			continue
+		}
+		seen[m.ModelUrl] = true
+		total++
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.ModelUrl, nil)
+		if err != nil {
+			continue
+		}
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+			online++
+		}
+	}
+	return online, total
+}
+
+// watchLocalModels polls the local servers in the background and calls
+// update with the result, for the tray's health indicator.
+func (a *App) watchLocalModels(update func(online, total int)) {
+	client := &http.Client{Timeout: 3 * time.Second}
+	for {
+		config, _ := a.LoadConfig()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		online, total := localModelHealth(ctx, client, config.Models)
+		cancel()
+		update(online, total)
+		time.Sleep(30 * time.Second)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// localStub serves /v1/models and, when messages is set, rejects empty
+// Messages requests the way a server with an Anthropic endpoint does.
+func localStub(t *testing.T, models string, messages bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/models" && r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, models)
+		case r.URL.Path == "/v1/messages" && messages:
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"model is required"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProbeLocalServers(t *testing.T) {
+	// Ollama lists models as "name:tag" and also serves /v1/messages
+	ollama := localStub(t, `{"object":"list","data":[{"id":"qwen3-coder:30b","object":"model","owned_by":"library"},{"id":"llama3.2:latest","object":"model","owned_by":"library"}]}`, true)
+	// LM Studio only speaks the OpenAI protocol
+	lmstudio := localStub(t, `{"data":[{"id":"qwen2.5-coder-7b-instruct","object":"model","owned_by":"organization_owner"}],"object":"list"}`, false)
+	// llama.cpp reports the loaded GGUF file
+	llamacpp := localStub(t, `{"object":"list","data":[{"id":"/models/gpt-oss-20b.gguf","object":"model","owned_by":"llamacpp","meta":{"n_ctx_train":131072}}]}`, false)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	got := probeLocalServers(context.Background(), http.DefaultClient, []localCandidate{
+		{Name: "Ollama", Url: ollama.URL + "/"},
+		{Name: "LM Studio", Url: lmstudio.URL},
+		{Name: "llama.cpp", Url: llamacpp.URL},
+		{Name: "Offline", Url: down.URL},
+	})
+	want := []LocalServer{
+		{Name: "Ollama", Url: ollama.URL, Protocol: ProtocolAnthropic, Models: []string{"qwen3-coder:30b", "llama3.2:latest"}},
+		{Name: "LM Studio", Url: lmstudio.URL + "/v1", Protocol: ProtocolOpenAI, Models: []string{"qwen2.5-coder-7b-instruct"}},
+		{Name: "llama.cpp", Url: llamacpp.URL + "/v1", Protocol: ProtocolOpenAI, Models: []string{"/models/gpt-oss-20b.gguf"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("probeLocalServers =\n%+v\nwant\n%+v", got, want)
+	}
+
+	configs := localModelConfigs(got[:1])
+	if len(configs) != 2 || configs[0].ModelName != "qwen3-coder:30b (Ollama)" || configs[0].ModelId != "qwen3-coder:30b" || !configs[0].IsLocal || configs[0].ApiKey != localApiKey {
+		t.Errorf("localModelConfigs = %+v", configs)
+	}
+}
+
+func TestProbeLocalServersSkipsNonModelServers(t *testing.T) {
+	// Something else listening on a default port, e.g. a dev web server on 8080
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html></html>")
+	}))
+	defer other.Close()
+	if got := probeLocalServers(context.Background(), http.DefaultClient, []localCandidate{{Name: "llama.cpp", Url: other.URL}}); len(got) != 0 {
+		t.Errorf("probeLocalServers = %+v, want none", got)
+	}
+}
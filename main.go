@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -19,6 +20,11 @@ func main() {
 	// Create an instance of the app structure
 	app := NewApp()
 
+	// Command-line tools run before, and instead of, the window
+	if len(os.Args) > 1 && os.Args[1] == "--make-bundle" {
+		os.Exit(makeBundleCommand(app, os.Args[2:], os.Stderr))
+	}
+
 	// Platform specific early initialization (like hiding console on Windows)
 	app.platformStartup()
 
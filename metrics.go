@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Latency buckets in seconds; model responses range from sub-second
+// count_tokens calls to multi-minute generations.
+var latencyBuckets = []float64{0.5, 1, 2, 5, 10, 30, 60, 120, 300}
+
+type metricLabels struct {
+	provider string
+	model    string
+	project  string
+}
+
+func (l metricLabels) String() string {
+	return fmt.Sprintf(`provider="%s",model="%s",project="%s"`, escapeLabel(l.provider), escapeLabel(l.model), escapeLabel(l.project))
+}
+
+type labelledCounter struct {
+	labels metricLabels
+	extra  string // Additional label pair such as status="200"
+}
+
+type histogram struct {
+	counts []int64 // Per bucket, non-cumulative; the last entry is +Inf
+	sum    float64
+	count  int64
+}
+
+// gatewayMetrics collects per-request statistics for the /metrics endpoint.
+type gatewayMetrics struct {
+	mu        sync.Mutex
+	requests  map[labelledCounter]int64
+	errors    map[labelledCounter]int64
+	tokens    map[labelledCounter]int64
+	failovers map[metricLabels]int64
+	latency   map[metricLabels]*histogram
+}
+
+// errorClass groups a response status into the classes reported by
+// cceasy_gateway_errors_total, or "" for a success.
+func errorClass(status int, err error) string {
+	switch {
+	case status == 0 || (err != nil && status < 400):
+		return "network"
+	case status == http.StatusTooManyRequests:
+		return "rate_limit"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "auth"
+	case status >= 500:
+		return "server"
+	case status >= 400:
+		return "client"
+	}
+	return ""
+}
+
+// observe records a request the gateway forwarded to the provider.
+func (m *gatewayMetrics) observe(labels metricLabels, status int, err error, duration time.Duration, usage tokenUsage, failovers int) {
+	m.record(labels, status, errorClass(status, err), duration, usage, failovers)
+}
+
+// reject records a request the gateway answered itself without contacting
+// the provider, counted under its own error class.
+func (m *gatewayMetrics) reject(labels metricLabels, status int, class string, duration time.Duration) {
+	m.record(labels, status, class, duration, tokenUsage{}, 0)
+}
+
+func (m *gatewayMetrics) record(labels metricLabels, status int, class string, duration time.Duration, usage tokenUsage, failovers int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.requests == nil {
+		m.requests = make(map[labelledCounter]int64)
+		m.errors = make(map[labelledCounter]int64)
+		m.tokens = make(map[labelledCounter]int64)
+		m.failovers = make(map[metricLabels]int64)
+		m.latency = make(map[metricLabels]*histogram)
+	}
+
+	m.requests[labelledCounter{labels, fmt.Sprintf(`status="%d"`, status)}]++
+	if class != "" {
+		m.errors[labelledCounter{labels, fmt.Sprintf(`class="%s"`, class)}]++
+	}
+	for kind, n := range map[string]int64{
+		"input":          usage.InputTokens,
+		"output":         usage.OutputTokens,
+		"cache_creation": usage.CacheCreationInputTokens,
+		"cache_read":     usage.CacheReadInputTokens,
+	} {
+		if n > 0 {
+			m.tokens[labelledCounter{labels, fmt.Sprintf(`type="%s"`, kind)}] += n
+		}
+	}
+	if failovers > 0 {
+		m.failovers[labels] += int64(failovers)
+	}
+
+	h := m.latency[labels]
+	if h == nil {
+		h = &histogram{counts: make([]int64, len(latencyBuckets)+1)}
+		m.latency[labels] = h
+	}
+	seconds := duration.Seconds()
+	i := sort.SearchFloat64s(latencyBuckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+// write renders the metrics in the Prometheus text exposition format.
+func (m *gatewayMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeCounters(w, "cceasy_gateway_requests_total", "Requests proxied by the gateway.", m.requests)
+	writeCounters(w, "cceasy_gateway_errors_total", "Failed requests by error class.", m.errors)
+	writeCounters(w, "cceasy_gateway_tokens_total", "Tokens reported by providers.", m.tokens)
+
+	fmt.Fprintln(w, "# HELP cceasy_gateway_failovers_total Requests retried with another API key after a rate limit.")
+	fmt.Fprintln(w, "# TYPE cceasy_gateway_failovers_total counter")
+	for _, labels := range sortedLabels(m.failovers) {
+		fmt.Fprintf(w, "cceasy_gateway_failovers_total{%s} %d\n", labels, m.failovers[labels])
+	}
+
+	fmt.Fprintln(w, "# HELP cceasy_gateway_request_duration_seconds Time from request to the end of the response.")
+	fmt.Fprintln(w, "# TYPE cceasy_gateway_request_duration_seconds histogram")
+	labelsList := make([]metricLabels, 0, len(m.latency))
+	for labels := range m.latency {
+		labelsList = append(labelsList, labels)
+	}
+	sort.Slice(labelsList, func(i, j int) bool { return labelsList[i].String() < labelsList[j].String() })
+	for _, labels := range labelsList {
+		h := m.latency[labels]
+		var cumulative int64
+		for i, bound := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "cceasy_gateway_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "cceasy_gateway_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "cceasy_gateway_request_duration_seconds_sum{%s} %g\n", labels, h.sum)
+		fmt.Fprintf(w, "cceasy_gateway_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+}
+
+func writeCounters(w io.Writer, name, help string, counters map[labelledCounter]int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	lines := make([]string, 0, len(counters))
+	for c, v := range counters {
+		lines = append(lines, fmt.Sprintf("%s{%s,%s} %d", name, c.labels, c.extra, v))
+	}
+	sort.Strings(lines)
+	for _, l := range lines {
+		fmt.Fprintln(w, l)
+	}
+}
+
+func sortedLabels(m map[metricLabels]int64) []metricLabels {
+	list := make([]metricLabels, 0, len(m))
+	for labels := range m {
+		list = append(list, labels)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].String() < list[j].String() })
+	return list
+}
+
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+// exchangeLabels labels an exchange with its provider, the model id it was
+// sent with and the current project.
+func exchangeLabels(config AppConfig, ex *exchange) metricLabels {
+	labels := metricLabels{provider: ex.model.ModelName}
+	var req struct {
+		Model string `json:"model"`
+	}
+	if json.Unmarshal(ex.reqBody, &req) == nil && req.Model != "" {
+		labels.model = req.Model
+	} else {
+		labels.model = getModelId(&ex.model)
+	}
+	for _, p := range config.Projects {
+		if p.Id == config.CurrentProject {
+			labels.project = p.Name
+			break
+		}
+	}
+	return labels
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGatewayMetricsCountRejectedRequests(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config := AppConfig{
+		CurrentModel: "Custom",
+		Models:       []ModelConfig{{ModelName: "Custom", ModelUrl: "http://127.0.0.1:1", ApiKey: "sk-test", IsCustom: true}},
+		Gateway: GatewayConfig{
+			Port:       18979,
+			Metrics:    true,
+			Redactions: []RedactionRule{{Name: "customer", Pattern: `CUST-[0-9]{6}`, Action: RedactBlock}},
+		},
+	}
+	g := newGateway(nil)
+	if err := g.apply(config); err != nil {
+		t.Fatal(err)
+	}
+	// The redaction rules start the listener, which later tests need free
+	defer g.apply(AppConfig{})
+	serve := func(body string) int {
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/messages", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	if code := serve(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"CUST-123456"}]}`); code != http.StatusBadRequest {
+		t.Fatalf("blocked request returned %d", code)
+	}
+	config.CurrentModel = "Missing"
+	if err := g.apply(config); err != nil {
+		t.Fatal(err)
+	}
+	if code := serve(`{}`); code != http.StatusBadGateway {
+		t.Fatalf("request without a model returned %d", code)
+	}
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		`cceasy_gateway_errors_total{provider="Custom",model="claude-sonnet-4-5",project="",class="redaction_block"} 1`,
+		`cceasy_gateway_errors_total{provider="",model="",project="",class="no_model"} 1`,
+		`cceasy_gateway_requests_total{provider="Custom",model="claude-sonnet-4-5",project="",status="400"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %s:\n%s", want, out)
+		}
+	}
+}
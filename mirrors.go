@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MirrorConfig selects where Node.js, Git for Windows and npm packages are
+// downloaded from. Empty fields use the built-in mirror order.
+type MirrorConfig struct {
+	Node        string `json:"node"`         // Node.js release mirror, e.g. https://npmmirror.com/mirrors/node
+	Git         string `json:"git"`          // Git for Windows release mirror, e.g. https://npmmirror.com/mirrors/git-for-windows
+	NpmRegistry string `json:"npm_registry"` // e.g. https://registry.npmmirror.com
+
+	// Accept a mirror's own SHASUMS256.txt when nodejs.org is unreachable
+	TrustMirrorChecksums bool `json:"trust_mirror_checksums"`
+}
+
+// Git for Windows release installed on Windows. Its tags carry a
+// ".windows.N" suffix.
+const (
+	gitVersion       = "2.47.1"
+	gitReleaseTag    = "v2.47.1.windows.1"
+	gitInstallerName = "Git-" + gitVersion + "-64-bit.exe"
+)
+
+var (
+	nodeMirrors = []string{
+		nodeDistURL,
+		"https://mirrors.tuna.tsinghua.edu.cn/nodejs-release",
+		"https://npmmirror.com/mirrors/node",
+	}
+	gitMirrors = []string{
+		"https://github.com/git-for-windows/git/releases/download",
+		"https://npmmirror.com/mirrors/git-for-windows",
+	}
+	npmRegistries = []string{
+		"https://registry.npmjs.org",
+		"https://registry.npmmirror.com",
+	}
+)
+
+// mirrorOrder puts the configured mirror first, followed by the built-in
+// ones as fallbacks.
+func mirrorOrder(preferred string, builtin []string) []string {
+	preferred = strings.TrimRight(strings.TrimSpace(preferred), "/")
+	order := []string{}
+	if preferred != "" {
+		order = append(order, preferred)
+	}
+	for _, m := range builtin {
+		if m != preferred {
+			order = append(order, m)
+		}
+	}
+	return order
+}
+
+// nodeDownloadURLs lists the mirrors for a Node.js release file in the
+// order they are tried.
+func (a *App) nodeDownloadURLs(version, fileName string) []string {
+	config, _ := a.LoadConfig()
+	mirrors := mirrorOrder(config.Mirrors.Node, nodeMirrors)
+	if nodeUnofficialBuild(fileName) {
+		// The release mirrors do not carry unofficial builds
+		mirrors = []string{nodeUnofficialURL}
+	}
+	urls := make([]string, len(mirrors))
+	for i, m := range mirrors {
+		urls[i] = fmt.Sprintf("%s/v%s/%s", m, version, fileName)
+	}
+	return urls
+}
+
+// gitDownloadURLs lists the mirrors for a Git for Windows release file.
+func (a *App) gitDownloadURLs(tag, fileName string) []string {
+	config, _ := a.LoadConfig()
+	mirrors := mirrorOrder(config.Mirrors.Git, gitMirrors)
+	urls := make([]string, len(mirrors))
+	for i, m := range mirrors {
+		urls[i] = fmt.Sprintf("%s/%s/%s", m, tag, fileName)
+	}
+	return urls
+}
+
+// npmRegistryArgs passes the configured registry to an npm command.
+func npmRegistryArgs(config AppConfig) []string {
+	if registry := strings.TrimSpace(config.Mirrors.NpmRegistry); registry != "" {
+		return []string{"--registry", registry}
+	}
+	return nil
+}
+
+// claudeInstallArgs are the npm arguments that install Claude Code, at
+// the given version or the latest when empty.
+func (a *App) claudeInstallArgs(version string) []string {
+	config, _ := a.LoadConfig()
+	pkg := claudePackage
+	if version != "" {
+		pkg += "@" + version
+	}
+	return append([]string{"install", "-g", pkg}, npmRegistryArgs(config)...)
+}
+
+// probeMirror measures how long a mirror takes to start answering for
+// a small file. Unreachable mirrors report an error.
+func probeMirror(ctx context.Context, client *http.Client, url string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", downloadUserAgent)
+	req.Header.Set("Range", "bytes=0-0")
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, fmt.Errorf("status: %s", resp.Status)
+	}
+	return time.Since(start), nil
+}
+
+// fastestMirror probes every mirror at once and returns the quickest one.
+// probePath maps a mirror to the URL that is requested from it.
+func fastestMirror(ctx context.Context, client *http.Client, mirrors []string, probePath func(string) string) (string, map[string]string) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	best := ""
+	var bestTime time.Duration
+	results := make(map[string]string)
+	for _, m := range mirrors {
+		wg.Add(1)
+		go func(m string) {
+			defer wg.Done()
+			d, err := probeMirror(ctx, client, probePath(m))
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				results[m] = err.Error()
+				return
+			}
+			results[m] = d.Round(time.Millisecond).String()
+			if best == "" || d < bestTime {
+				best, bestTime = m, d
+			}
+		}(m)
+	}
+	wg.Wait()
+	return best, results
+}
+
+// DetectFastestMirrors probes the built-in mirrors for Node.js, Git and npm,
+// saves the quickest of each in the config and returns the result.
+func (a *App) DetectFastestMirrors() (MirrorConfig, error) {
+	config, err := a.LoadConfig()
+	if err != nil {
+		return MirrorConfig{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	client := a.httpClient(0)
+
+	version := config.Node.targetVersion()
+	probes := []struct {
+		name    string
+		mirrors []string
+		path    func(string) string
+		target  *string
+	}{
+		{"Node.js", nodeMirrors, func(m string) string { return fmt.Sprintf("%s/v%s/SHASUMS256.txt", m, version) }, &config.Mirrors.Node},
+		{"Git", gitMirrors, func(m string) string { return fmt.Sprintf("%s/%s/%s", m, gitReleaseTag, gitInstallerName) }, &config.Mirrors.Git},
+		{"npm", npmRegistries, func(m string) string { return m + "/@anthropic-ai%2fclaude-code" }, &config.Mirrors.NpmRegistry},
+	}
+	for _, p := range probes {
+		best, results := fastestMirror(ctx, client, p.mirrors, p.path)
+		for _, m := range p.mirrors {
+			a.log(fmt.Sprintf("%s mirror %s: %s", p.name, m, results[m]))
+		}
+		if best != "" {
+			*p.target = best
+			a.log(fmt.Sprintf("Fastest %s mirror: %s", p.name, best))
+		}
+	}
+
+	if err := a.SaveConfig(config); err != nil {
+		return MirrorConfig{}, err
+	}
+	return config.Mirrors, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NetworkConfig routes traffic through a proxy and trusts extra root
+// certificates, for networks with an intercepting HTTPS proxy.
+type NetworkConfig struct {
+	Proxy    string `json:"proxy"`     // e.g. http://proxy.example.com:8080
+	NoProxy  string `json:"no_proxy"`  // Comma-separated hosts or domains that bypass the proxy
+	CaBundle string `json:"ca_bundle"` // PEM file with extra root certificates
+}
+
+// effectiveNetwork applies a model's overrides on top of the global settings.
+func effectiveNetwork(global NetworkConfig, m *ModelConfig) NetworkConfig {
+	n := global
+	if m == nil || m.Network == nil {
+		return n
+	}
+	if m.Network.Proxy != "" {
+		n.Proxy = m.Network.Proxy
+	}
+	if m.Network.NoProxy != "" {
+		n.NoProxy = m.Network.NoProxy
+	}
+	if m.Network.CaBundle != "" {
+		n.CaBundle = m.Network.CaBundle
+	}
+	return n
+}
+
+// bypassProxy reports whether host is local or matches the no-proxy list.
+// Entries match the host itself and its subdomains; "*" matches everything.
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, "*")
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTransport builds an HTTP transport for the given settings. Without a
+// proxy the usual HTTPS_PROXY environment variables still apply.
+func newTransport(n NetworkConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if n.Proxy != "" {
+		proxyURL, err := url.Parse(n.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", n.Proxy)
+		}
+		noProxy := n.NoProxy
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL.Hostname(), noProxy) {
+				return nil, nil
+			}
+			return proxyURL, nil
+		}
+	}
+
+	if n.CaBundle != "" {
+		pem, err := os.ReadFile(n.CaBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", n.CaBundle)
+		}
+		t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return t, nil
+}
+
+// transportCache shares one transport, and so one connection pool, per
+// distinct network configuration. A transport is rebuilt when its CA bundle
+// file changes, since the config keeps the same path.
+type transportCache struct {
+	mu         sync.Mutex
+	transports map[NetworkConfig]cachedTransport
+}
+
+type cachedTransport struct {
+	transport *http.Transport
+	caStamp   string
+}
+
+// caBundleStamp identifies the current contents of a CA bundle file by its
+// size and modification time.
+func caBundleStamp(path string) string {
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", info.Size(), info.ModTime().UnixNano())
+}
+
+func (c *transportCache) get(n NetworkConfig) (*http.Transport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stamp := caBundleStamp(n.CaBundle)
+	cached, ok := c.transports[n]
+	if ok && cached.caStamp == stamp {
+		return cached.transport, nil
+	}
+	t, err := newTransport(n)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cached.transport.CloseIdleConnections()
+	}
+	if c.transports == nil {
+		c.transports = make(map[NetworkConfig]cachedTransport)
+	}
+	c.transports[n] = cachedTransport{transport: t, caStamp: stamp}
+	return t, nil
+}
+
+var sharedTransports = &transportCache{}
+
+type networkContextKey struct{}
+
+// withNetwork tags an outgoing request with the network settings it must use.
+func withNetwork(ctx context.Context, n NetworkConfig) context.Context {
+	return context.WithValue(ctx, networkContextKey{}, n)
+}
+
+func networkFromContext(ctx context.Context) NetworkConfig {
+	n, _ := ctx.Value(networkContextKey{}).(NetworkConfig)
+	return n
+}
+
+// httpClient returns a client for cceasy's own downloads and update checks
+// that honours the global network settings.
+func (a *App) httpClient(timeout time.Duration) *http.Client {
+	config, _ := a.LoadConfig()
+	t, err := sharedTransports.get(config.Network)
+	if err != nil {
+		a.log("Ignoring network settings: " + err.Error())
+		return &http.Client{Timeout: timeout}
+	}
+	return &http.Client{Transport: t, Timeout: timeout}
+}
+
+// sessionNetworkEnv returns the environment variables that carry the
+// network settings into a launched Claude Code session.
+func sessionNetworkEnv(config AppConfig, m *ModelConfig) map[string]string {
+	n := effectiveNetwork(config.Network, m)
+	env := make(map[string]string)
+	if n.Proxy != "" {
+		env["HTTPS_PROXY"] = n.Proxy
+		env["HTTP_PROXY"] = n.Proxy
+		// The local gateway must always be reached directly
+		noProxy := "localhost,127.0.0.1"
+		if n.NoProxy != "" {
+			noProxy = n.NoProxy + "," + noProxy
+		}
+		env["NO_PROXY"] = noProxy
+	}
+	if n.CaBundle != "" {
+		env["NODE_EXTRA_CA_CERTS"] = n.CaBundle
+	}
+	return env
+}
+
+// sortedEnvKeys keeps generated launch scripts stable between runs.
+func sortedEnvKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestCA(t *testing.T, path, name string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert
+}
+
+func TestTransportCacheReloadsChangedCABundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corp-ca.pem")
+	writeTestCA(t, path, "Old Corp CA")
+	n := NetworkConfig{CaBundle: path}
+	cache := &transportCache{}
+
+	first, err := cache.get(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := cache.get(n); again != first {
+		t.Fatal("unchanged config built a new transport")
+	}
+
+	// The proxy's CA was rotated in place; the config still names the same file
+	cert := writeTestCA(t, path, "New Corp CA")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+
+	second, err := cache.get(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first {
+		t.Fatal("CA bundle change did not rebuild the transport")
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: second.TLSClientConfig.RootCAs}); err != nil {
+		t.Errorf("new CA not trusted: %v", err)
+	}
+}
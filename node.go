@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	defaultNodeVersion    = "22.14.0"
+	defaultMinNodeVersion = "18.0.0" // Oldest Node.js Claude Code runs on
+)
+
+// NodeConfig selects the Node.js version cceasy installs and the oldest
+// version it accepts from the system.
+type NodeConfig struct {
+	Version    string `json:"version"`     // e.g. "22.14.0"
+	MinVersion string `json:"min_version"` // e.g. "18.0.0"
+}
+
+func (c NodeConfig) targetVersion() string {
+	if v := strings.TrimPrefix(strings.TrimSpace(c.Version), "v"); v != "" {
+		return v
+	}
+	return defaultNodeVersion
+}
+
+func (c NodeConfig) minimumVersion() string {
+	if v := strings.TrimPrefix(strings.TrimSpace(c.MinVersion), "v"); v != "" {
+		return v
+	}
+	return defaultMinNodeVersion
+}
+
+var nodeVersionPattern = regexp.MustCompile(`^v?(\d+(?:\.\d+){0,2})`)
+
+// nodeVersion runs `node --version` for the given binary.
+func nodeVersion(nodePath string) (string, error) {
+	cmd := exec.Command(nodePath, "--version")
+	hideCommandWindow(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// nodeVersionAtLeast reports whether a `node --version` output such as
+// "v20.11.1" meets the minimum. An unreadable version never does.
+func nodeVersionAtLeast(version, minimum string) bool {
+	m := nodeVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return false
+	}
+	return compareVersions(m[1], minimum) >= 0
+}
+
+const (
+	libcGlibc = "glibc"
+	libcMusl  = "musl"
+)
+
+// Musl builds of Node.js are only published as unofficial builds.
+const nodeUnofficialURL = "https://unofficial-builds.nodejs.org/download/release"
+
+// nodeArchNames maps Go architectures to the names used in Node.js
+// release file names.
+var nodeArchNames = map[string]string{
+	"amd64":   "x64",
+	"arm64":   "arm64",
+	"arm":     "armv7l",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+}
+
+// nodeDistFile is the name of the Node.js release archive cceasy installs
+// on the given platform. libc selects the musl build on Linux.
+func nodeDistFile(version, goos, goarch, libc string) (string, error) {
+	arch, ok := nodeArchNames[goarch]
+	// macOS, Windows and musl builds exist for x64 and arm64 only
+	if ok && (goos != "linux" || libc == libcMusl) && arch != "x64" && arch != "arm64" {
+		ok = false
+	}
+	if !ok {
+		return "", fmt.Errorf("no Node.js release for %s/%s", goos, goarch)
+	}
+	switch goos {
+	case "linux":
+		if libc == libcMusl {
+			return fmt.Sprintf("node-v%s-linux-%s-musl.tar.xz", version, arch), nil
+		}
+		return fmt.Sprintf("node-v%s-linux-%s.tar.xz", version, arch), nil
+	case "darwin":
+		return fmt.Sprintf("node-v%s-darwin-%s.tar.gz", version, arch), nil
+	case "windows":
+		return fmt.Sprintf("node-v%s-win-%s.zip", version, arch), nil
+	}
+	return "", fmt.Errorf("no Node.js release for %s/%s", goos, goarch)
+}
+
+// nodeUnofficialBuild reports whether a release file is only published on
+// unofficial-builds.nodejs.org.
+func nodeUnofficialBuild(fileName string) bool {
+	return strings.Contains(fileName, "-musl.")
+}
+
+// verifyNodeRuns checks that an installed node binary starts. A build for
+// the wrong libc or architecture extracts fine but cannot be executed.
+func verifyNodeRuns(nodePath string) (string, error) {
+	version, err := nodeVersion(nodePath)
+	if err != nil {
+		return "", fmt.Errorf("installed Node.js does not run on this system: %v", err)
+	}
+	return version, nil
+}
+
+func getPrivateNodeDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cceasy", "node")
+}
+
+// nodeConfig returns the Node.js settings from the saved config.
+func (a *App) nodeConfig() NodeConfig {
+	config, _ := a.LoadConfig()
+	return config.Node
+}
+
+// offerPrivateNode tells the frontend the system Node.js is too old, so it
+// can offer InstallPrivateNode to the user.
+func (a *App) offerPrivateNode(nodePath, version string) {
+	minimum := a.nodeConfig().minimumVersion()
+	if version == "" {
+		version = "(unknown version)"
+	}
+	a.log(fmt.Sprintf("Node.js %s at %s is older than the required %s. A private Node.js can be installed under %s instead.", version, nodePath, minimum, getPrivateNodeDir()))
+	runtime.EventsEmit(a.ctx, "node-outdated", map[string]string{
+		"path":    nodePath,
+		"version": version,
+		"minimum": minimum,
+	})
+}
+
+// InstallPrivateNode installs the configured Node.js version under
+// ~/.cceasy/node, leaving the system Node.js untouched, and then reruns
+// the environment check so Claude Code is installed with it.
+func (a *App) InstallPrivateNode() error {
+	dir := getPrivateNodeDir()
+	if err := a.installPrivateNode(dir); err != nil {
+		a.log("Private Node.js installation failed: " + err.Error())
+		return err
+	}
+	a.log("Node.js installed to " + dir)
+	a.CheckEnvironment()
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// getUserNpmPrefix is the per-user npm prefix Claude Code is installed into
+// when the global prefix needs administrator rights.
+func getUserNpmPrefix() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cceasy", "npm-global")
+}
+
+// npmBinDir is where npm links the commands of packages installed into prefix.
+func npmBinDir(prefix string) string {
+	if runtime.GOOS == "windows" {
+		return prefix
+	}
+	return filepath.Join(prefix, "bin")
+}
+
+// getUserNpmBinDir is the command directory of the per-user prefix. It is
+// put on PATH by the environment check and LaunchClaude.
+func getUserNpmBinDir() string {
+	return npmBinDir(getUserNpmPrefix())
+}
+
+// userPrefixHasClaude reports whether Claude Code is installed in the
+// per-user prefix.
+func userPrefixHasClaude() bool {
+	_, err := os.Stat(filepath.Join(npmGlobalDir(getUserNpmPrefix()), filepath.FromSlash(claudePackage)))
+	return err == nil
+}
+
+// globalPrefixWritable reports whether `npm install -g` can write to the
+// global prefix without administrator rights.
+func globalPrefixWritable(npm string) bool {
+	prefix, err := commandOutput(npm, "config", "get", "prefix")
+	if err != nil || prefix == "" {
+		return false
+	}
+	dir := npmGlobalDir(prefix)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		dir = prefix
+	}
+	return dirWritable(dir)
+}
+
+// claudePrefixArgs are the npm arguments that select the prefix Claude Code
+// lives in: the per-user prefix once it holds Claude Code or when the
+// global prefix is not writable, otherwise none.
+func claudePrefixArgs(npm string) []string {
+	if userPrefixHasClaude() || !globalPrefixWritable(npm) {
+		return []string{"--prefix", getUserNpmPrefix()}
+	}
+	return nil
+}
+
+// MigrateClaudeToUserPrefix reinstalls a globally installed Claude Code into
+// the per-user prefix, so later updates need no administrator rights, and
+// then tries to remove the global copy.
+func (a *App) MigrateClaudeToUserPrefix() error {
+	npm := findNpm()
+	if npm == "" {
+		return fmt.Errorf("npm not found")
+	}
+	if userPrefixHasClaude() {
+		return fmt.Errorf("Claude Code is already installed in %s", getUserNpmPrefix())
+	}
+	version := claudeVersionIn(npm, nil)
+	if version == "" {
+		return fmt.Errorf("no global Claude Code installation found")
+	}
+
+	a.log(fmt.Sprintf("Installing Claude Code %s into %s...", version, getUserNpmPrefix()))
+	if err := os.MkdirAll(getUserNpmPrefix(), 0755); err != nil {
+		return err
+	}
+	args := append(a.claudeInstallArgs(version), "--prefix", getUserNpmPrefix())
+	if out, err := a.runNpm(npm, args); err != nil {
+		return fmt.Errorf("installation failed: %v: %s", err, string(out))
+	}
+	prependPath(getUserNpmBinDir())
+
+	a.log("Removing the global Claude Code installation...")
+	if out, err := a.runNpm(npm, []string{"uninstall", "-g", claudePackage}); err != nil {
+		// The per-user copy comes first on PATH, so a leftover global copy is harmless
+		a.log("Could not remove the global copy, it will be shadowed instead: " + string(out))
+	}
+	a.log("Claude Code migrated to " + getUserNpmPrefix())
+	return nil
+}
+
+// prependPath puts dir first on the PATH of this process.
+func prependPath(dir string) {
+	path := os.Getenv("PATH")
+	for _, p := range filepath.SplitList(path) {
+		if p == dir {
+			return
+		}
+	}
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+}
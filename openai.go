@@ -0,0 +1,568 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	ProtocolAnthropic = "anthropic"
+	ProtocolOpenAI    = "openai"
+)
+
+// isOpenAI reports whether the model's endpoint speaks the OpenAI chat
+// completions protocol and must be reached through the gateway translator.
+func (m ModelConfig) isOpenAI() bool {
+	return strings.EqualFold(m.Protocol, ProtocolOpenAI)
+}
+
+// forwardOpenAI serves an Anthropic Messages request from an OpenAI
+// compatible /chat/completions endpoint, translating both directions.
+func (g *Gateway) forwardOpenAI(w http.ResponseWriter, r *http.Request, ex *exchange) {
+	if !isMessagesPath(r.URL.Path) {
+		writeGatewayError(w, http.StatusNotFound, "not_found_error", fmt.Sprintf("cceasy gateway: %s is not supported for OpenAI-protocol models", r.URL.Path))
+		return
+	}
+
+	body, stream, err := anthropicToOpenAIRequest(ex.reqBody, getModelId(&ex.model))
+	if err != nil {
+		ex.err = err
+		writeGatewayError(w, http.StatusBadRequest, "invalid_request_error", "cceasy gateway: "+err.Error())
+		return
+	}
+
+	upstream := strings.TrimRight(getBaseUrl(&ex.model), "/") + "/chat/completions"
+	resp := g.roundTrip(w, r, ex, upstream, body, func(h http.Header, key string) {
+		h.Set("Content-Type", "application/json")
+		h.Set("Authorization", "Bearer "+key)
+		if ua := r.Header.Get("User-Agent"); ua != "" {
+			h.Set("User-Agent", ua)
+		}
+		if stream {
+			h.Set("Accept", "text/event-stream")
+		}
+	})
+	if resp == nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	ex.status = resp.StatusCode
+	ex.respHeader = resp.Header.Clone()
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		out := openAIErrorToAnthropic(resp.StatusCode, data)
+		ex.respBody.Write(out)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.StatusCode)
+		w.Write(out)
+		return
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			ex.err = err
+			writeGatewayError(w, http.StatusBadGateway, "api_error", "cceasy gateway: "+err.Error())
+			return
+		}
+		out, err := openAIToAnthropicResponse(data)
+		if err != nil {
+			ex.err = err
+			writeGatewayError(w, http.StatusBadGateway, "api_error", "cceasy gateway: "+err.Error())
+			return
+		}
+		ex.respBody.Write(out)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.StatusCode)
+		w.Write(out)
+		return
+	}
+
+	ex.stream = true
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(resp.StatusCode)
+	flusher, _ := w.(http.Flusher)
+
+	translator := &openAIStreamTranslator{}
+	emit := func(events []sseEvent) {
+		for _, ev := range events {
+			ex.events = append(ex.events, ev)
+			if _, err := w.Write(encodeSSE(ev)); err != nil && ex.err == nil {
+				ex.err = err
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	decoder := &sseDecoder{onEvent: func(ev sseEvent) {
+		emit(translator.feed(ev))
+	}}
+	if _, err := io.Copy(decoder, resp.Body); err != nil {
+		ex.err = err
+	}
+	decoder.Close()
+	emit(translator.finish())
+}
+
+// anthropicToOpenAIRequest converts a Messages API request body into a chat
+// completions request. Claude model ids are replaced with modelName since the
+// upstream cannot serve them.
+func anthropicToOpenAIRequest(body []byte, modelName string) ([]byte, bool, error) {
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, false, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	out := make(map[string]interface{})
+	model := jsonString(req["model"])
+	if model == "" || strings.HasPrefix(model, "claude-") {
+		model = modelName
+	}
+	out["model"] = model
+
+	messages := []interface{}{}
+	if system := anthropicText(req["system"]); system != "" {
+		messages = append(messages, map[string]interface{}{"role": "system", "content": system})
+	}
+	rawMessages, _ := req["messages"].([]interface{})
+	for _, raw := range rawMessages {
+		msg, _ := raw.(map[string]interface{})
+		role := jsonString(msg["role"])
+		if role == "assistant" {
+			messages = append(messages, assistantToOpenAI(msg["content"]))
+		} else {
+			messages = append(messages, userToOpenAI(msg["content"])...)
+		}
+	}
+	out["messages"] = messages
+
+	if v, ok := req["max_tokens"]; ok {
+		out["max_tokens"] = v
+	}
+	for _, k := range []string{"temperature", "top_p"} {
+		if v, ok := req[k]; ok {
+			out[k] = v
+		}
+	}
+	if stop, ok := req["stop_sequences"].([]interface{}); ok && len(stop) > 0 {
+		out["stop"] = stop
+	}
+
+	stream, _ := req["stream"].(bool)
+	if stream {
+		out["stream"] = true
+		out["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	if tools, ok := req["tools"].([]interface{}); ok && len(tools) > 0 {
+		converted := []interface{}{}
+		for _, raw := range tools {
+			tool, _ := raw.(map[string]interface{})
+			if _, ok := tool["input_schema"]; !ok {
+				// Server tools such as web_search have no OpenAI equivalent
+				continue
+			}
+			converted = append(converted, map[string]interface{}{
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":        tool["name"],
+					"description": tool["description"],
+					"parameters":  tool["input_schema"],
+				},
+			})
+		}
+		if len(converted) > 0 {
+			out["tools"] = converted
+			if choice, ok := req["tool_choice"].(map[string]interface{}); ok {
+				switch jsonString(choice["type"]) {
+				case "auto":
+					out["tool_choice"] = "auto"
+				case "any":
+					out["tool_choice"] = "required"
+				case "none":
+					out["tool_choice"] = "none"
+				case "tool":
+					out["tool_choice"] = map[string]interface{}{
+						"type":     "function",
+						"function": map[string]interface{}{"name": choice["name"]},
+					}
+				}
+			}
+		}
+	}
+
+	data, err := json.Marshal(out)
+	return data, stream, err
+}
+
+// anthropicText flattens a string or a list of text blocks into plain text.
+func anthropicText(v interface{}) string {
+	switch c := v.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var parts []string
+		for _, raw := range c {
+			block, _ := raw.(map[string]interface{})
+			if jsonString(block["type"]) == "text" {
+				parts = append(parts, jsonString(block["text"]))
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
+
+// userToOpenAI converts a user turn. tool_result blocks become separate
+// "tool" messages, which OpenAI requires directly after the assistant turn.
+func userToOpenAI(content interface{}) []interface{} {
+	blocks, ok := content.([]interface{})
+	if !ok {
+		return []interface{}{map[string]interface{}{"role": "user", "content": anthropicText(content)}}
+	}
+
+	var toolMessages []interface{}
+	var parts []interface{}
+	for _, raw := range blocks {
+		block, _ := raw.(map[string]interface{})
+		switch jsonString(block["type"]) {
+		case "text":
+			parts = append(parts, map[string]interface{}{"type": "text", "text": block["text"]})
+		case "image":
+			source, _ := block["source"].(map[string]interface{})
+			url := jsonString(source["url"])
+			if jsonString(source["type"]) == "base64" {
+				url = fmt.Sprintf("data:%s;base64,%s", jsonString(source["media_type"]), jsonString(source["data"]))
+			}
+			parts = append(parts, map[string]interface{}{
+				"type":      "image_url",
+				"image_url": map[string]interface{}{"url": url},
+			})
+		case "tool_result":
+			text := anthropicText(block["content"])
+			if isError, _ := block["is_error"].(bool); isError {
+				text = "Error: " + text
+			}
+			toolMessages = append(toolMessages, map[string]interface{}{
+				"role":         "tool",
+				"tool_call_id": block["tool_use_id"],
+				"content":      text,
+			})
+		}
+	}
+
+	messages := toolMessages
+	if len(parts) > 0 {
+		// Plain text turns are sent as a string for providers without multi-part support
+		if len(parts) == 1 && jsonString(parts[0].(map[string]interface{})["type"]) == "text" {
+			messages = append(messages, map[string]interface{}{"role": "user", "content": parts[0].(map[string]interface{})["text"]})
+		} else {
+			messages = append(messages, map[string]interface{}{"role": "user", "content": parts})
+		}
+	}
+	return messages
+}
+
+func assistantToOpenAI(content interface{}) map[string]interface{} {
+	msg := map[string]interface{}{"role": "assistant"}
+	blocks, ok := content.([]interface{})
+	if !ok {
+		msg["content"] = anthropicText(content)
+		return msg
+	}
+
+	var text []string
+	var toolCalls []interface{}
+	for _, raw := range blocks {
+		block, _ := raw.(map[string]interface{})
+		switch jsonString(block["type"]) {
+		case "text":
+			text = append(text, jsonString(block["text"]))
+		case "tool_use":
+			args, _ := json.Marshal(block["input"])
+			toolCalls = append(toolCalls, map[string]interface{}{
+				"id":   block["id"],
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":      block["name"],
+					"arguments": string(args),
+				},
+			})
+		}
+	}
+	if len(text) > 0 {
+		msg["content"] = strings.Join(text, "\n")
+	} else {
+		msg["content"] = nil
+	}
+	if len(toolCalls) > 0 {
+		msg["tool_calls"] = toolCalls
+	}
+	return msg
+}
+
+type openAIUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+type openAIToolCall struct {
+	Index    int    `json:"index"`
+	Id       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIResponse struct {
+	Id      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content   string           `json:"content"`
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"message"`
+		Delta struct {
+			Content   string           `json:"content"`
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+func openAIStopReason(reason string) string {
+	switch reason {
+	case "length":
+		return "max_tokens"
+	case "tool_calls", "function_call":
+		return "tool_use"
+	default:
+		return "end_turn"
+	}
+}
+
+func openAIToAnthropicResponse(body []byte) ([]byte, error) {
+	var resp openAIResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid upstream response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("upstream response has no choices")
+	}
+
+	choice := resp.Choices[0]
+	content := []interface{}{}
+	if choice.Message.Content != "" {
+		content = append(content, map[string]interface{}{"type": "text", "text": choice.Message.Content})
+	}
+	for _, call := range choice.Message.ToolCalls {
+		var input interface{} = map[string]interface{}{}
+		if call.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &input); err != nil {
+				input = map[string]interface{}{}
+			}
+		}
+		content = append(content, map[string]interface{}{
+			"type":  "tool_use",
+			"id":    call.Id,
+			"name":  call.Function.Name,
+			"input": input,
+		})
+	}
+
+	usage := openAIUsage{}
+	if resp.Usage != nil {
+		usage = *resp.Usage
+	}
+	return json.Marshal(map[string]interface{}{
+		"id":            resp.Id,
+		"type":          "message",
+		"role":          "assistant",
+		"model":         resp.Model,
+		"content":       content,
+		"stop_reason":   openAIStopReason(choice.FinishReason),
+		"stop_sequence": nil,
+		"usage": map[string]interface{}{
+			"input_tokens":  usage.PromptTokens,
+			"output_tokens": usage.CompletionTokens,
+		},
+	})
+}
+
+// openAIErrorToAnthropic rewraps an upstream error in the Anthropic error shape.
+func openAIErrorToAnthropic(status int, body []byte) []byte {
+	message := strings.TrimSpace(string(body))
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Error.Message != "" {
+		message = resp.Error.Message
+	}
+
+	errType := "api_error"
+	switch {
+	case status == http.StatusUnauthorized:
+		errType = "authentication_error"
+	case status == http.StatusForbidden:
+		errType = "permission_error"
+	case status == http.StatusNotFound:
+		errType = "not_found_error"
+	case status == http.StatusTooManyRequests:
+		errType = "rate_limit_error"
+	case status < 500:
+		errType = "invalid_request_error"
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":  "error",
+		"error": map[string]string{"type": errType, "message": message},
+	})
+	return data
+}
+
+// openAIStreamTranslator turns chat completion chunks into the event
+// sequence of a streamed Messages API response.
+type openAIStreamTranslator struct {
+	started    bool
+	finished   bool
+	blockIndex int
+	blockType  string // "", "text" or "tool_use"
+	toolIndex  int
+	toolId     string
+	stopReason string
+	usage      openAIUsage
+}
+
+func (t *openAIStreamTranslator) event(eventType string, payload map[string]interface{}) sseEvent {
+	payload["type"] = eventType
+	data, _ := json.Marshal(payload)
+	return sseEvent{Event: eventType, Data: string(data)}
+}
+
+func (t *openAIStreamTranslator) start(id, model string) []sseEvent {
+	t.started = true
+	t.blockIndex = -1
+	return []sseEvent{t.event("message_start", map[string]interface{}{
+		"message": map[string]interface{}{
+			"id":            id,
+			"type":          "message",
+			"role":          "assistant",
+			"model":         model,
+			"content":       []interface{}{},
+			"stop_reason":   nil,
+			"stop_sequence": nil,
+			"usage":         map[string]interface{}{"input_tokens": 0, "output_tokens": 0},
+		},
+	})}
+}
+
+func (t *openAIStreamTranslator) closeBlock() []sseEvent {
+	if t.blockType == "" {
+		return nil
+	}
+	t.blockType = ""
+	return []sseEvent{t.event("content_block_stop", map[string]interface{}{"index": t.blockIndex})}
+}
+
+func (t *openAIStreamTranslator) openBlock(blockType string, block map[string]interface{}) []sseEvent {
+	events := t.closeBlock()
+	t.blockIndex++
+	t.blockType = blockType
+	return append(events, t.event("content_block_start", map[string]interface{}{
+		"index":         t.blockIndex,
+		"content_block": block,
+	}))
+}
+
+func (t *openAIStreamTranslator) feed(ev sseEvent) []sseEvent {
+	if t.finished || strings.TrimSpace(ev.Data) == "[DONE]" {
+		return nil
+	}
+	var chunk openAIResponse
+	if err := json.Unmarshal([]byte(ev.Data), &chunk); err != nil {
+		return nil
+	}
+
+	var events []sseEvent
+	if !t.started {
+		events = append(events, t.start(chunk.Id, chunk.Model)...)
+	}
+	if chunk.Usage != nil {
+		t.usage = *chunk.Usage
+	}
+	if len(chunk.Choices) == 0 {
+		return events
+	}
+
+	choice := chunk.Choices[0]
+	if choice.Delta.Content != "" {
+		if t.blockType != "text" {
+			events = append(events, t.openBlock("text", map[string]interface{}{"type": "text", "text": ""})...)
+		}
+		events = append(events, t.event("content_block_delta", map[string]interface{}{
+			"index": t.blockIndex,
+			"delta": map[string]interface{}{"type": "text_delta", "text": choice.Delta.Content},
+		}))
+	}
+	for _, call := range choice.Delta.ToolCalls {
+		// Some providers (DashScope/Qwen) repeat the id on every delta of a
+		// call, so only a new index or a different id starts a new block
+		if t.blockType != "tool_use" || call.Index != t.toolIndex || (call.Id != "" && call.Id != t.toolId) {
+			t.toolIndex = call.Index
+			t.toolId = call.Id
+			events = append(events, t.openBlock("tool_use", map[string]interface{}{
+				"type":  "tool_use",
+				"id":    call.Id,
+				"name":  call.Function.Name,
+				"input": map[string]interface{}{},
+			})...)
+		}
+		if call.Function.Arguments != "" {
+			events = append(events, t.event("content_block_delta", map[string]interface{}{
+				"index": t.blockIndex,
+				"delta": map[string]interface{}{"type": "input_json_delta", "partial_json": call.Function.Arguments},
+			}))
+		}
+	}
+	if choice.FinishReason != "" {
+		t.stopReason = openAIStopReason(choice.FinishReason)
+	}
+	return events
+}
+
+// finish closes the message once the upstream stream has ended.
+func (t *openAIStreamTranslator) finish() []sseEvent {
+	if t.finished {
+		return nil
+	}
+	t.finished = true
+
+	var events []sseEvent
+	if !t.started {
+		events = append(events, t.start("", "")...)
+	}
+	events = append(events, t.closeBlock()...)
+	if t.stopReason == "" {
+		t.stopReason = "end_turn"
+	}
+	events = append(events, t.event("message_delta", map[string]interface{}{
+		"delta": map[string]interface{}{"stop_reason": t.stopReason, "stop_sequence": nil},
+		"usage": map[string]interface{}{
+			"input_tokens":  t.usage.PromptTokens,
+			"output_tokens": t.usage.CompletionTokens,
+		},
+	}))
+	events = append(events, t.event("message_stop", map[string]interface{}{}))
+	return events
+}
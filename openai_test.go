@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// checkGolden compares got with testdata/<name>, rewriting the file when
+// the tests run with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want := readTestdata(t, name)
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match:\n%s", name, diffLines(strings.Split(string(want), "\n"), strings.Split(string(got), "\n")))
+	}
+}
+
+func indentJSON(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes()
+}
+
+// translateOpenAIStream runs an OpenAI SSE body through the stream
+// translator and returns the Anthropic events it produced.
+func translateOpenAIStream(data []byte) []sseEvent {
+	translator := &openAIStreamTranslator{}
+	var events []sseEvent
+	decoder := &sseDecoder{onEvent: func(ev sseEvent) {
+		events = append(events, translator.feed(ev)...)
+	}}
+	decoder.Write(data)
+	decoder.Close()
+	return append(events, translator.finish()...)
+}
+
+func encodeEvents(events []sseEvent) []byte {
+	var out bytes.Buffer
+	for _, ev := range events {
+		out.Write(encodeSSE(ev))
+	}
+	return out.Bytes()
+}
+
+func TestAnthropicToOpenAIRequest(t *testing.T) {
+	out, stream, err := anthropicToOpenAIRequest(readTestdata(t, "openai/request.json"), "qwen3-coder-plus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stream {
+		t.Error("stream flag not detected")
+	}
+	checkGolden(t, "openai/request.golden.json", indentJSON(t, out))
+}
+
+func TestOpenAIToAnthropicResponse(t *testing.T) {
+	out, err := openAIToAnthropicResponse(readTestdata(t, "openai/response.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "openai/response.golden.json", indentJSON(t, out))
+}
+
+func TestOpenAIStreamTranslator(t *testing.T) {
+	tests := []struct {
+		input    string
+		golden   string
+		toolUses []string
+	}{
+		{"openai/stream.sse", "openai/stream.golden.sse", []string{"call_1"}},
+		// DashScope/Qwen repeat the tool call id on every delta
+		{"openai/stream_repeated_id.sse", "openai/stream_repeated_id.golden.sse", []string{"call_a", "call_b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			events := translateOpenAIStream(readTestdata(t, tt.input))
+			checkGolden(t, tt.golden, encodeEvents(events))
+
+			message, err := reassembleMessage(events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := message["content"].([]interface{})
+			var ids []string
+			for _, c := range content {
+				block, _ := c.(map[string]interface{})
+				if block["type"] != "tool_use" {
+					continue
+				}
+				ids = append(ids, block["id"].(string))
+				if input, ok := block["input"].(map[string]interface{}); !ok || len(input) == 0 {
+					t.Errorf("tool_use %v has no input: %v", block["id"], block["input"])
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.toolUses, ",") {
+				t.Errorf("tool_use blocks = %v, want %v", ids, tt.toolUses)
+			}
+		})
+	}
+}
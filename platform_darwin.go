@@ -4,8 +4,6 @@ package main
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -34,7 +32,7 @@ func (a *App) CheckEnvironment() {
 		commonPaths = append(commonPaths, filepath.Join(home, ".npm-global/bin"))
 		
 		// Add local node bin to PATH
-		commonPaths = append([]string{localBinDir}, commonPaths...)
+		commonPaths = append([]string{localBinDir, getUserNpmBinDir()}, commonPaths...)
 
 		newPathParts := strings.Split(envPath, ":")
 		pathChanged := false
@@ -116,15 +114,16 @@ func (a *App) CheckEnvironment() {
 
 		a.log("Node.js found at: " + nodePath)
 
-		// 4. Search for npm
-		npmExec, err := exec.LookPath("npm")
-		if err != nil {
-			localNpmPath := filepath.Join(localBinDir, "npm")
-			if _, err := os.Stat(localNpmPath); err == nil {
-				npmExec = localNpmPath
-			}
+		// A system Node.js that is too old is left alone; the user is offered a private one instead
+		version, _ := nodeVersion(nodePath)
+		if !nodeVersionAtLeast(version, a.nodeConfig().minimumVersion()) {
+			a.offerPrivateNode(nodePath, version)
+			wails_runtime.EventsEmit(a.ctx, "env-check-done")
+			return
 		}
-		
+
+		// 4. Search for npm
+		npmExec := findNpm()
 		if npmExec == "" {
 			a.log("npm not found.")
 			wails_runtime.EventsEmit(a.ctx, "env-check-done")
@@ -150,27 +149,7 @@ func (a *App) CheckEnvironment() {
 			}
 		}
 
-		if claudePath == "" {
-			a.log("Claude Code not found. Installing...")
-			
-			// Use local npm install -g if we are using manual node
-			// If npm is in ~/.cceasy/node/bin/npm, it should default global install to ~/.cceasy/node/lib...
-			
-			installCmd := exec.Command(npmExec, "install", "-g", "@anthropic-ai/claude-code")
-			installCmd.Env = os.Environ() // Explicitly pass environment with updated PATH
-			if err := installCmd.Run(); err != nil {
-				a.log("Standard installation failed. Trying with sudo...")
-				script := fmt.Sprintf(`do shell script "%s install -g @anthropic-ai/claude-code" with administrator privileges`, npmExec)
-				adminCmd := exec.Command("osascript", "-e", script)
-				if err := adminCmd.Run(); err != nil {
-					a.log("Installation failed.")
-				} else {
-					a.log("Claude Code installed.")
-				}
-			} else {
-				a.log("Claude Code installed.")
-			}
-		}
+		a.ensureClaudeCode(npmExec, claudePath != "")
 
 		a.log("Environment check complete.")
 		wails_runtime.EventsEmit(a.ctx, "env-check-done")
@@ -178,43 +157,35 @@ func (a *App) CheckEnvironment() {
 }
 
 func (a *App) installNodeJSManually(destDir string) error {
-	arch := runtime.GOARCH
-	if arch == "amd64" {
-		arch = "x64" // Node uses x64 for amd64
+	if ok, err := a.installNodeFromBundle(destDir); ok {
+		return err
 	}
-	
-	version := "v22.12.0"
-	fileName := fmt.Sprintf("node-%s-darwin-%s.tar.gz", version, arch)
-	url := fmt.Sprintf("https://nodejs.org/dist/%s/%s", version, fileName)
-	
-	a.log("Downloading Node.js from " + url)
-	
-	resp, err := http.Get(url)
+
+	version := a.nodeConfig().targetVersion()
+	fileName, err := nodeDistFile(version, runtime.GOOS, runtime.GOARCH, detectLibc())
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
-	}
 
-	// Create a temp file for the tarball
-	tempFile, err := os.CreateTemp("", "node-*.tar.gz")
+	a.log(fmt.Sprintf("Downloading Node.js v%s...", version))
+
+	archive := filepath.Join(os.TempDir(), fileName)
+	url, err := a.download(archive, a.nodeDownloadURLs(version, fileName))
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %v", err)
+		return err
 	}
-	defer os.Remove(tempFile.Name())
-	defer tempFile.Close()
+	defer os.Remove(archive)
 
-	a.log("Saving download to temp file...")
-	if _, err := io.Copy(tempFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to save download: %v", err)
+	if err := a.verifyNodeDownload(archive, version, fileName, url); err != nil {
+		return err
 	}
-	
-	// Close file to ensure all data is flushed before tar reads it
-	tempFile.Close()
 
+	return a.extractNodeArchive(archive, destDir)
+}
+
+// extractNodeArchive replaces destDir with the contents of a Node.js
+// .tar.gz distribution.
+func (a *App) extractNodeArchive(archive, destDir string) error {
 	// Clean destination directory if it exists to avoid conflicts
 	if _, err := os.Stat(destDir); err == nil {
 		a.log("Cleaning existing Node.js directory...")
@@ -229,7 +200,7 @@ func (a *App) installNodeJSManually(destDir string) error {
 	
 	// Using native tar command on the file
 	// -x: extract, -z: gunzip, -f: file, -C: directory, --strip-components 1: remove root folder
-	cmd := exec.Command("tar", "-xzf", tempFile.Name(), "-C", destDir, "--strip-components", "1")
+	cmd := exec.Command("tar", "-xzf", archive, "-C", destDir, "--strip-components", "1")
 	
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
@@ -246,6 +217,59 @@ func (a *App) installNodeJSManually(destDir string) error {
 	return nil
 }
 
+// detectLibc returns "" as macOS has a single system C library.
+func detectLibc() string {
+	return ""
+}
+
+func findNpm() string {
+	if path, err := exec.LookPath("npm"); err == nil {
+		return path
+	}
+	localNpm := filepath.Join(getPrivateNodeDir(), "bin", "npm")
+	if _, err := os.Stat(localNpm); err == nil {
+		return localNpm
+	}
+	return ""
+}
+
+// runNpm runs an npm command, retrying with administrator privileges when
+// npm was denied access to the global prefix. Commands aimed at a per-user
+// prefix are never escalated.
+func (a *App) runNpm(npm string, args []string) ([]byte, error) {
+	cmd := exec.Command(npm, args...)
+	cmd.Env = os.Environ() // Explicitly pass environment with updated PATH
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "EACCES") || contains(args, "--prefix") {
+		return out, err
+	}
+
+	a.log("npm was denied access to the global prefix. Retrying with administrator privileges...")
+	quoted := []string{shellQuote(npm)}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	script := fmt.Sprintf(`do shell script %s with administrator privileges`, appleScriptString(strings.Join(quoted, " ")))
+	return exec.Command("osascript", "-e", script).CombinedOutput()
+}
+
+// shellQuote quotes s as a single sh word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (a *App) installPrivateNode(destDir string) error {
+	return a.installNodeJSManually(destDir)
+}
+
+func hideCommandWindow(cmd *exec.Cmd) {
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -290,8 +314,12 @@ func (a *App) LaunchClaude(yoloMode bool, projectDir string) {
 		return
 	}
 
-	baseUrl := getBaseUrl(selectedModel)
-	claudePath, _ := exec.LookPath("claude")
+	baseUrl := resolveBaseUrl(config, selectedModel)
+	// Prefer the per-user npm prefix, which shadows a global install
+	claudePath := filepath.Join(getUserNpmBinDir(), "claude")
+	if _, err := os.Stat(claudePath); err != nil {
+		claudePath, _ = exec.LookPath("claude")
+	}
 	if claudePath == "" {
 		// Try fallback to local bin
 		home, _ := os.UserHomeDir()
@@ -313,10 +341,14 @@ func (a *App) LaunchClaude(yoloMode bool, projectDir string) {
 	var sb strings.Builder
 	sb.WriteString("#!/bin/bash\n")
 	// Export local bin to PATH
-	sb.WriteString(fmt.Sprintf("export PATH=\"%s:$PATH\"\n", localBinDir))
+	sb.WriteString(fmt.Sprintf("export PATH=\"%s:%s:$PATH\"\n", getUserNpmBinDir(), localBinDir))
 	// Export Auth Tokens
-	sb.WriteString(fmt.Sprintf("export ANTHROPIC_AUTH_TOKEN=\"%s\"\n", selectedModel.ApiKey))
+	sb.WriteString(fmt.Sprintf("export ANTHROPIC_AUTH_TOKEN=\"%s\"\n", resolveAuthToken(config, selectedModel)))
 	sb.WriteString(fmt.Sprintf("export ANTHROPIC_BASE_URL=\"%s\"\n", baseUrl))
+	networkEnv := sessionNetworkEnv(config, selectedModel)
+	for _, k := range sortedEnvKeys(networkEnv) {
+		sb.WriteString(fmt.Sprintf("export %s=\"%s\"\n", k, networkEnv[k]))
+	}
 	
 	// Navigate to project directory
 	if projectDir != "" {
@@ -360,5 +392,34 @@ end try`, safeLaunchPath)
 	}
 }
 
+// detectTerminals lists the terminal apps found. LaunchClaude uses Terminal.
+func detectTerminals() []string {
+	found := []string{}
+	apps := []struct {
+		name  string
+		paths []string
+	}{
+		{"Terminal", []string{"/System/Applications/Utilities/Terminal.app", "/Applications/Utilities/Terminal.app"}},
+		{"iTerm", []string{"/Applications/iTerm.app"}},
+	}
+	for _, app := range apps {
+		for _, p := range app.paths {
+			if _, err := os.Stat(p); err == nil {
+				found = append(found, app.name)
+				break
+			}
+		}
+	}
+	return found
+}
+
 func (a *App) syncToSystemEnv(config AppConfig) {
-}
\ No newline at end of file
+}
+
+func (a *App) showNotification(title, message string) {
+	escape := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "\\", "\\\\"), "\"", "\\\"")
+	}
+	script := fmt.Sprintf(`display notification "%s" with title "%s"`, escape(message), escape(title))
+	go exec.Command("osascript", "-e", script).Run()
+}
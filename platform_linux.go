@@ -5,14 +5,11 @@ package main
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 
 	wails_runtime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -33,7 +30,7 @@ func (a *App) CheckEnvironment() {
 		commonPaths := []string{"/usr/local/bin", "/usr/bin", "/bin", "/usr/sbin", "/sbin"}
 		
 		// Add local node bin to PATH
-		commonPaths = append([]string{localBinDir}, commonPaths...)
+		commonPaths = append([]string{localBinDir, getUserNpmBinDir()}, commonPaths...)
 
 		newPathParts := strings.Split(envPath, ":")
 		pathChanged := false
@@ -87,15 +84,16 @@ func (a *App) CheckEnvironment() {
 
 		a.log("Node.js found at: " + nodePath)
 
-		// 4. Search for npm
-		npmExec, err := exec.LookPath("npm")
-		if err != nil {
-			localNpmPath := filepath.Join(localBinDir, "npm")
-			if _, err := os.Stat(localNpmPath); err == nil {
-				npmExec = localNpmPath
-			}
+		// A system Node.js that is too old is left alone; the user is offered a private one instead
+		version, _ := nodeVersion(nodePath)
+		if !nodeVersionAtLeast(version, a.nodeConfig().minimumVersion()) {
+			a.offerPrivateNode(nodePath, version)
+			wails_runtime.EventsEmit(a.ctx, "env-check-done")
+			return
 		}
-		
+
+		// 4. Search for npm
+		npmExec := findNpm()
 		if npmExec == "" {
 			a.log("npm not found.")
 			wails_runtime.EventsEmit(a.ctx, "env-check-done")
@@ -121,16 +119,7 @@ func (a *App) CheckEnvironment() {
 			}
 		}
 
-		if claudePath == "" {
-			a.log("Claude Code not found. Installing...")
-			installCmd := exec.Command(npmExec, "install", "-g", "@anthropic-ai/claude-code")
-			installCmd.Env = os.Environ()
-			if out, err := installCmd.CombinedOutput(); err != nil {
-				a.log("Installation failed: " + string(out))
-			} else {
-				a.log("Claude Code installed.")
-			}
-		}
+		a.ensureClaudeCode(npmExec, claudePath != "")
 
 		a.log("Environment check complete.")
 		wails_runtime.EventsEmit(a.ctx, "env-check-done")
@@ -138,71 +127,36 @@ func (a *App) CheckEnvironment() {
 }
 
 func (a *App) installNodeJSManually(destDir string) error {
-	arch := runtime.GOARCH
-	if arch == "amd64" {
-		arch = "x64"
-	}
-	
-	version := "22.14.0"
-	fileName := fmt.Sprintf("node-v%s-linux-%s.tar.xz", version, arch)
-	
-downloadURL := fmt.Sprintf("https://nodejs.org/dist/v%s/%s", version, fileName)
-	if strings.HasPrefix(strings.ToLower(a.CurrentLanguage), "zh") {
-		// Use a mirror in China for faster download
-		downloadURL = fmt.Sprintf("https://mirrors.tuna.tsinghua.edu.cn/nodejs-release/v%s/%s", version, fileName)
-	}
-
-	a.log(fmt.Sprintf("Downloading Node.js v%s from %s...", version, downloadURL))
-	
-	req, err := http.NewRequest("GET", downloadURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create download request: %w", err)
+	if ok, err := a.installNodeFromBundle(destDir); ok {
+		return err
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	version := a.nodeConfig().targetVersion()
+	libc := detectLibc()
+	fileName, err := nodeDistFile(version, runtime.GOOS, runtime.GOARCH, libc)
 	if err != nil {
-		return fmt.Errorf("network error during download: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
 	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s", resp.Status)
-	}
+	a.log(fmt.Sprintf("Downloading Node.js v%s for %s/%s (%s)...", version, runtime.GOOS, runtime.GOARCH, libc))
 
-	size := resp.ContentLength
-	tempFile, err := os.CreateTemp("", "node-*.tar.xz")
+	archive := filepath.Join(os.TempDir(), fileName)
+	downloadURL, err := a.download(archive, a.nodeDownloadURLs(version, fileName))
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %v", err)
+		return err
 	}
-	defer os.Remove(tempFile.Name())
-	defer tempFile.Close()
-
-	var downloaded int64
-	buffer := make([]byte, 32768)
-	lastReport := time.Now()
-
-	for {
-		n, err := resp.Body.Read(buffer)
-		if n > 0 {
-			tempFile.Write(buffer[:n])
-			downloaded += int64(n)
-			if size > 0 && time.Since(lastReport) > 500*time.Millisecond {
-				percent := float64(downloaded) / float64(size) * 100
-				a.log(fmt.Sprintf("Downloading Node.js (%.1f%%): %d/%d bytes", percent, downloaded, size))
-				lastReport = time.Now()
-			}
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("interrupted download: %v", err)
-		}
+	defer os.Remove(archive)
+
+	if err := a.verifyNodeDownload(archive, version, fileName, downloadURL); err != nil {
+		return err
 	}
-	tempFile.Close()
 
+	return a.extractNodeArchive(archive, destDir)
+}
+
+// extractNodeArchive replaces destDir with the contents of a Node.js
+// .tar.xz distribution.
+func (a *App) extractNodeArchive(archive, destDir string) error {
 	if _, err := os.Stat(destDir); err == nil {
 		a.log("Cleaning existing Node.js directory...")
 		os.RemoveAll(destDir)
@@ -212,15 +166,65 @@ downloadURL := fmt.Sprintf("https://nodejs.org/dist/v%s/%s", version, fileName)
 		return err
 	}
 	
-a.log("Extracting Node.js...")
-	cmd := exec.Command("tar", "-xJf", tempFile.Name(), "-C", destDir, "--strip-components", "1")
+	a.log("Extracting Node.js...")
+	cmd := exec.Command("tar", "-xJf", archive, "-C", destDir, "--strip-components", "1")
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("tar extraction failed: %v, output: %s", err, string(out))
 	}
 
+	version, err := verifyNodeRuns(filepath.Join(destDir, "bin", "node"))
+	if err != nil {
+		// Leave nothing behind that the environment check would pick up
+		os.RemoveAll(destDir)
+		if detectLibc() == libcMusl {
+			return fmt.Errorf("%w. Musl builds of Node.js need libstdc++, e.g. `apk add libstdc++`", err)
+		}
+		return err
+	}
+	a.log("Node.js " + version + " runs.")
 	return nil
 }
 
+// detectLibc tells glibc systems from musl-based ones such as Alpine,
+// which need a different Node.js build.
+func detectLibc() string {
+	if out, err := exec.Command("getconf", "GNU_LIBC_VERSION").Output(); err == nil && strings.HasPrefix(string(out), "glibc") {
+		return libcGlibc
+	}
+	// musl's ldd prints its version to stderr and exits non-zero
+	if out, _ := exec.Command("ldd", "--version").CombinedOutput(); strings.Contains(strings.ToLower(string(out)), "musl") {
+		return libcMusl
+	}
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) > 0 {
+		return libcMusl
+	}
+	return libcGlibc
+}
+
+func findNpm() string {
+	if path, err := exec.LookPath("npm"); err == nil {
+		return path
+	}
+	localNpm := filepath.Join(getPrivateNodeDir(), "bin", "npm")
+	if _, err := os.Stat(localNpm); err == nil {
+		return localNpm
+	}
+	return ""
+}
+
+func (a *App) runNpm(npm string, args []string) ([]byte, error) {
+	cmd := exec.Command(npm, args...)
+	cmd.Env = os.Environ()
+	return cmd.CombinedOutput()
+}
+
+func (a *App) installPrivateNode(destDir string) error {
+	return a.installNodeJSManually(destDir)
+}
+
+func hideCommandWindow(cmd *exec.Cmd) {
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -253,13 +257,16 @@ func (a *App) LaunchClaude(yoloMode bool, projectDir string) {
 		return
 	}
 
-	baseUrl := getBaseUrl(selectedModel)
+	baseUrl := resolveBaseUrl(config, selectedModel)
 	
 	home, _ := os.UserHomeDir()
 	localBinDir := filepath.Join(home, ".cceasy", "node", "bin")
 
-	// Search for Claude
-	claudePath, _ := exec.LookPath("claude")
+	// Search for Claude, preferring the per-user npm prefix
+	claudePath := filepath.Join(getUserNpmBinDir(), "claude")
+	if _, err := os.Stat(claudePath); err != nil {
+		claudePath, _ = exec.LookPath("claude")
+	}
 	if claudePath == "" {
 		// 1. Try local bin
 		localClaude := filepath.Join(localBinDir, "claude")
@@ -296,14 +303,18 @@ func (a *App) LaunchClaude(yoloMode bool, projectDir string) {
 	sb.WriteString("#!/bin/bash\n")
 	
 	// Add both local and global bin to PATH in script
-	pathDirs := []string{localBinDir}
+	pathDirs := []string{getUserNpmBinDir(), localBinDir}
 	if claudePath != "" {
 		pathDirs = append(pathDirs, filepath.Dir(claudePath))
 	}
 	sb.WriteString(fmt.Sprintf("export PATH=\"%s:$PATH\"\n", strings.Join(pathDirs, ":")))
 	
-	sb.WriteString(fmt.Sprintf("export ANTHROPIC_AUTH_TOKEN=\"%s\"\n", selectedModel.ApiKey))
+	sb.WriteString(fmt.Sprintf("export ANTHROPIC_AUTH_TOKEN=\"%s\"\n", resolveAuthToken(config, selectedModel)))
 	sb.WriteString(fmt.Sprintf("export ANTHROPIC_BASE_URL=\"%s\"\n", baseUrl))
+	networkEnv := sessionNetworkEnv(config, selectedModel)
+	for _, k := range sortedEnvKeys(networkEnv) {
+		sb.WriteString(fmt.Sprintf("export %s=\"%s\"\n", k, networkEnv[k]))
+	}
 
 	if projectDir != "" {
 		sb.WriteString(fmt.Sprintf("cd \"%s\" || exit\n", projectDir))
@@ -340,25 +351,11 @@ func (a *App) LaunchClaude(yoloMode bool, projectDir string) {
 		return
 	}
 
-	// Terminal fallbacks
-	terminals := []struct {
-		name string
-		args []string
-	}{
-		{"x-terminal-emulator", []string{"-e", launchScriptPath}},
-		{"gnome-terminal", []string{"--", launchScriptPath}},
-		{"konsole", []string{"--", launchScriptPath}},
-		{"xfce4-terminal", []string{"-e", launchScriptPath}},
-		{"lxterminal", []string{"-e", launchScriptPath}},
-		{"mate-terminal", []string{"-e", launchScriptPath}},
-		{"xterm", []string{"-e", launchScriptPath}},
-	}
-
 	launched := false
 	for _, t := range terminals {
 		if _, err := exec.LookPath(t.name); err == nil {
 			a.log("Attempting to launch via " + t.name)
-			if err := exec.Command(t.name, t.args...).Start(); err == nil {
+			if err := exec.Command(t.name, t.flag, launchScriptPath).Start(); err == nil {
 				launched = true
 				break
 			}
@@ -370,5 +367,37 @@ func (a *App) LaunchClaude(yoloMode bool, projectDir string) {
 	}
 }
 
+// Terminal fallbacks, in order, with the flag that runs a script
+var terminals = []struct {
+	name string
+	flag string
+}{
+	{"x-terminal-emulator", "-e"},
+	{"gnome-terminal", "--"},
+	{"konsole", "--"},
+	{"xfce4-terminal", "-e"},
+	{"lxterminal", "-e"},
+	{"mate-terminal", "-e"},
+	{"xterm", "-e"},
+}
+
+// detectTerminals lists the terminal emulators LaunchClaude can use.
+func detectTerminals() []string {
+	found := []string{}
+	for _, t := range terminals {
+		if _, err := exec.LookPath(t.name); err == nil {
+			found = append(found, t.name)
+		}
+	}
+	return found
+}
+
 func (a *App) syncToSystemEnv(config AppConfig) {
-}
\ No newline at end of file
+}
+
+func (a *App) showNotification(title, message string) {
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return
+	}
+	go exec.Command("notify-send", "-a", "Claude Code Easy Suite", title, message).Run()
+}
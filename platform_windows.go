@@ -4,8 +4,6 @@ package main
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -60,6 +58,22 @@ func (a *App) updatePathForNode() {
 		}
 	}
 
+	// Claude Code in the per-user npm prefix shadows a global install
+	userNpmDir := getUserNpmPrefix()
+	if _, err := os.Stat(userNpmDir); err == nil {
+		if !strings.Contains(strings.ToLower(currentPath), strings.ToLower(userNpmDir)) {
+			newPath = userNpmDir + string(os.PathListSeparator) + newPath
+		}
+	}
+
+	// A private Node.js takes priority over the system one
+	privateNodeDir := getPrivateNodeDir()
+	if _, err := os.Stat(filepath.Join(privateNodeDir, "node.exe")); err == nil {
+		if !strings.Contains(strings.ToLower(currentPath), strings.ToLower(privateNodeDir)) {
+			newPath = privateNodeDir + string(os.PathListSeparator) + newPath
+		}
+	}
+
 	if newPath != currentPath {
 		os.Setenv("PATH", newPath)
 		a.log("Updated PATH environment variable for the current process.")
@@ -70,18 +84,26 @@ func (a *App) CheckEnvironment() {
 	go func() {
 		a.log("Checking Node.js installation...")
 
+		// Make a private Node.js visible before looking for node
+		a.updatePathForNode()
+
 		// Check for node
-		nodeCmd := exec.Command("node", "--version")
-		nodeCmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-		if err := nodeCmd.Run(); err != nil {
+		version, err := nodeVersion("node")
+		if err != nil {
 			a.log("Node.js not found. Downloading and installing...")
 			if err := a.installNodeJS(); err != nil {
 				a.log("Failed to install Node.js: " + err.Error())
 				return
 			}
 			a.log("Node.js installed successfully.")
+		} else if !nodeVersionAtLeast(version, a.nodeConfig().minimumVersion()) {
+			// A system Node.js that is too old is left alone; the user is offered a private one instead
+			nodePath, _ := exec.LookPath("node")
+			a.offerPrivateNode(nodePath, version)
+			runtime.EventsEmit(a.ctx, "env-check-done")
+			return
 		} else {
-			a.log("Node.js is installed.")
+			a.log("Node.js " + version + " is installed.")
 		}
 
 		// Update path for the current process anyway to ensure npm is found
@@ -112,10 +134,7 @@ func (a *App) CheckEnvironment() {
 			a.log("Git is installed.")
 		}
 
-		npmPath := "npm"
-		if _, err := exec.LookPath("npm"); err != nil {
-			npmPath = `C:\Program Files\nodejs\npm.cmd`
-		}
+		npmPath := findNpm()
 
 		a.log("Checking Claude Code...")
 
@@ -123,34 +142,7 @@ func (a *App) CheckEnvironment() {
 		claudeCheckCmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 		claudeExists := claudeCheckCmd.Run() == nil
 
-		if !claudeExists {
-			a.log("Claude Code not found. Installing...")
-			cmdStr := fmt.Sprintf("%s install -g @anthropic-ai/claude-code", npmPath)
-			a.log("Running command: " + cmdStr)
-			
-			installCmd := exec.Command(npmPath, "install", "-g", "@anthropic-ai/claude-code")
-			installCmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-
-			if out, err := installCmd.CombinedOutput(); err != nil {
-				a.log("Failed to install Claude Code: " + string(out))
-			} else {
-				a.log("Claude Code installed successfully. Refreshing environment...")
-				a.updatePathForNode()
-			}
-		} else {
-			a.log("Claude Code found. Checking for updates...")
-			cmdStr := fmt.Sprintf("%s install -g @anthropic-ai/claude-code", npmPath)
-			a.log("Running command: " + cmdStr)
-
-			installCmd := exec.Command(npmPath, "install", "-g", "@anthropic-ai/claude-code")
-			installCmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-			if out, err := installCmd.CombinedOutput(); err != nil {
-				a.log("Failed to update Claude Code: " + string(out))
-			} else {
-				a.log("Claude Code updated successfully.")
-				a.updatePathForNode()
-			}
-		}
+		a.ensureClaudeCode(npmPath, claudeExists)
 
 		a.log("Environment check complete.")
 		runtime.EventsEmit(a.ctx, "env-check-done")
@@ -158,46 +150,36 @@ func (a *App) CheckEnvironment() {
 }
 
 func (a *App) installNodeJS() error {
+	// Offline bundles carry the zip distribution, which is installed privately
+	if ok, err := a.installNodeFromBundle(getPrivateNodeDir()); ok {
+		if err == nil {
+			a.updatePathForNode()
+		}
+		return err
+	}
+
 	arch := os.Getenv("PROCESSOR_ARCHITECTURE")
 	nodeArch := "x64"
 	if arch == "ARM64" || os.Getenv("PROCESSOR_ARCHITEW6432") == "ARM64" {
 		nodeArch = "arm64"
 	}
 
-	// Using a more recent version
-	nodeVersion := "22.14.0"
+	nodeVersion := a.nodeConfig().targetVersion()
 	fileName := fmt.Sprintf("node-v%s-%s.msi", nodeVersion, nodeArch)
-	
-	downloadURL := fmt.Sprintf("https://nodejs.org/dist/v%s/%s", nodeVersion, fileName)
-	if strings.HasPrefix(strings.ToLower(a.CurrentLanguage), "zh") && nodeArch != "arm64" {
-		// Use a mirror in China for faster download (only for x64 as arm64 might not be synced)
-		downloadURL = fmt.Sprintf("https://mirrors.tuna.tsinghua.edu.cn/nodejs-release/v%s/%s", nodeVersion, fileName)
-	}
 
 	a.log(fmt.Sprintf("Downloading Node.js %s for %s...", nodeVersion, nodeArch))
 
-	// Pre-check if the file exists and is accessible
-	client := &http.Client{Timeout: 10 * time.Second}
-	headReq, _ := http.NewRequest("HEAD", downloadURL, nil)
-	headReq.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	headResp, err := client.Do(headReq)
-	if err != nil || headResp.StatusCode != http.StatusOK {
-		status := "Unknown"
-		if headResp != nil {
-			status = headResp.Status
-		}
-		return fmt.Errorf("Node.js installer is not accessible (Status: %s). Please check your internet connection or mirror availability.", status)
-	}
-	headResp.Body.Close()
-
-	tempDir := os.TempDir()
-	msiPath := filepath.Join(tempDir, fileName)
-
-	if err := a.downloadFile(msiPath, downloadURL); err != nil {
+	msiPath := filepath.Join(os.TempDir(), fileName)
+	downloadURL, err := a.download(msiPath, a.nodeDownloadURLs(nodeVersion, fileName))
+	if err != nil {
 		return fmt.Errorf("error downloading Node.js installer: %w", err)
 	}
 	defer os.Remove(msiPath)
 
+	if err := a.verifyNodeDownload(msiPath, nodeVersion, fileName, downloadURL); err != nil {
+		return err
+	}
+
 	a.log("Installing Node.js (this may take a moment, please grant administrator permission if prompted)...")
 	// Use /passive for basic UI or /qn for completely silent.
 	// Adding ALLUSERS=1 to ensure it's in the standard path.
@@ -214,6 +196,89 @@ func (a *App) installNodeJS() error {
 	return nil
 }
 
+// installPrivateNode unpacks the Node.js zip distribution into destDir,
+// which needs no administrator permission.
+func (a *App) installPrivateNode(destDir string) error {
+	if ok, err := a.installNodeFromBundle(destDir); ok {
+		if err == nil {
+			a.updatePathForNode()
+		}
+		return err
+	}
+
+	nodeArch := "x64"
+	if os.Getenv("PROCESSOR_ARCHITECTURE") == "ARM64" || os.Getenv("PROCESSOR_ARCHITEW6432") == "ARM64" {
+		nodeArch = "arm64"
+	}
+
+	version := a.nodeConfig().targetVersion()
+	fileName := fmt.Sprintf("node-v%s-win-%s.zip", version, nodeArch)
+
+	a.log(fmt.Sprintf("Downloading Node.js %s for %s...", version, nodeArch))
+
+	zipPath := filepath.Join(os.TempDir(), fileName)
+	downloadURL, err := a.download(zipPath, a.nodeDownloadURLs(version, fileName))
+	if err != nil {
+		return fmt.Errorf("error downloading Node.js: %w", err)
+	}
+	defer os.Remove(zipPath)
+
+	if err := a.verifyNodeDownload(zipPath, version, fileName, downloadURL); err != nil {
+		return err
+	}
+
+	if err := a.extractNodeArchive(zipPath, destDir); err != nil {
+		return err
+	}
+	a.updatePathForNode()
+	return nil
+}
+
+// extractNodeArchive replaces destDir with the contents of a Node.js zip
+// distribution.
+func (a *App) extractNodeArchive(archive, destDir string) error {
+	if _, err := os.Stat(destDir); err == nil {
+		a.log("Cleaning existing Node.js directory...")
+		os.RemoveAll(destDir)
+	}
+
+	a.log("Extracting Node.js...")
+	if err := extractZip(archive, destDir, 1); err != nil {
+		return fmt.Errorf("zip extraction failed: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "node.exe")); err != nil {
+		return fmt.Errorf("verification failed: node.exe not found after extraction")
+	}
+	return nil
+}
+
+// detectLibc returns "" as Node.js for Windows has no libc variants.
+func detectLibc() string {
+	return ""
+}
+
+func findNpm() string {
+	if path, err := exec.LookPath("npm"); err == nil {
+		return path
+	}
+	return `C:\Program Files\nodejs\npm.cmd`
+}
+
+// runNpm runs an npm command and refreshes PATH for anything it installed.
+func (a *App) runNpm(npm string, args []string) ([]byte, error) {
+	cmd := exec.Command(npm, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		a.updatePathForNode()
+	}
+	return out, err
+}
+
+func hideCommandWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
+
 func (a *App) updatePathForGit() {
 	// Common git paths
 	gitPaths := []string{
@@ -239,26 +304,26 @@ func (a *App) updatePathForGit() {
 }
 
 func (a *App) installGitBash() error {
-	gitVersion := "2.47.1"
-	// git-for-windows versioning can be tricky. v2.47.1.windows.1
-	fullVersion := "v2.47.1.windows.1"
-	fileName := fmt.Sprintf("Git-%s-64-bit.exe", gitVersion)
-	
-	downloadURL := fmt.Sprintf("https://github.com/git-for-windows/git/releases/download/%s/%s", fullVersion, fileName)
-	if strings.HasPrefix(strings.ToLower(a.CurrentLanguage), "zh") {
-		downloadURL = fmt.Sprintf("https://npmmirror.com/mirrors/git-for-windows/%s/%s", fullVersion, fileName)
+	fileName := gitInstallerName
+	// Without a pinned hash the installer could not be verified
+	expected, err := gitChecksum(fileName)
+	if err != nil {
+		return err
 	}
-	
-	a.log(fmt.Sprintf("Downloading Git %s...", gitVersion))
 
-	tempDir := os.TempDir()
-	exePath := filepath.Join(tempDir, fileName)
+	a.log(fmt.Sprintf("Downloading Git %s...", gitVersion))
 
-	if err := a.downloadFile(exePath, downloadURL); err != nil {
+	exePath := filepath.Join(os.TempDir(), fileName)
+	if _, err := a.download(exePath, a.gitDownloadURLs(gitReleaseTag, fileName)); err != nil {
 		return fmt.Errorf("error downloading Git installer: %w", err)
 	}
 	defer os.Remove(exePath)
 
+	if err := verifyFile(exePath, expected); err != nil {
+		return err
+	}
+	a.log("Checksum verified for " + fileName)
+
 	a.log("Installing Git (this may take a moment, please grant administrator permission if prompted)...")
 	// Silent installation
 	cmd := exec.Command(exePath, "/VERYSILENT", "/NORESTART", "/NOCANCEL", "/SP-", "/CLOSEAPPLICATIONS", "/RESTARTAPPLICATIONS")
@@ -275,63 +340,6 @@ func (a *App) installGitBash() error {
 	return nil
 }
 
-func (a *App) downloadFile(filepath string, url string) error {
-	a.log(fmt.Sprintf("Requesting URL: %s", url))
-	
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create download request: %w", err)
-	}
-	// Add User-Agent to avoid 403 Forbidden from some mirrors/CDNs
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-
-	// Use a client with timeout for the connection phase
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("network error during download: %v. Please check your internet connection or firewall settings.", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s. The file might not be available on this server.", resp.Status)
-	}
-
-	size := resp.ContentLength
-	out, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create local file: %w", err)
-	}
-	defer out.Close()
-
-	var downloaded int64
-	buffer := make([]byte, 32768)
-	lastReport := time.Now()
-
-	for {
-		n, err := resp.Body.Read(buffer)
-		if n > 0 {
-			out.Write(buffer[:n])
-			downloaded += int64(n)
-			if size > 0 && time.Since(lastReport) > 500*time.Millisecond {
-				percent := float64(downloaded) / float64(size) * 100
-				a.log(fmt.Sprintf("Downloading Node.js (%.1f%%): %d/%d bytes", percent, downloaded, size))
-				lastReport = time.Now()
-			}
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("interrupted download: %v. The connection was lost during data transfer.", err)
-		}
-	}
-
-	return nil
-}
-
 func (a *App) restartApp() {
 	executable, err := os.Executable()
 	if err != nil {
@@ -360,12 +368,34 @@ func (a *App) LaunchClaude(yoloMode bool, projectDir string) {
 	}
 	
 	cmd.Env = os.Environ()
+	if config, err := a.LoadConfig(); err == nil {
+		for _, m := range config.Models {
+			if m.ModelName == config.CurrentModel {
+				networkEnv := sessionNetworkEnv(config, &m)
+				for _, k := range sortedEnvKeys(networkEnv) {
+					cmd.Env = append(cmd.Env, k+"="+networkEnv[k])
+				}
+				break
+			}
+		}
+	}
 	
 	if err := cmd.Start(); err != nil {
 		a.log("Failed to launch Claude: " + err.Error())
 	}
 }
 
+// detectTerminals lists the shells and terminals found. LaunchClaude uses cmd.exe.
+func detectTerminals() []string {
+	found := []string{}
+	for _, name := range []string{"cmd.exe", "wt.exe", "powershell.exe", "pwsh.exe"} {
+		if _, err := exec.LookPath(name); err == nil {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
 func (a *App) syncToSystemEnv(config AppConfig) {
 	var selectedModel *ModelConfig
 	for _, m := range config.Models {
@@ -379,15 +409,16 @@ func (a *App) syncToSystemEnv(config AppConfig) {
 		return
 	}
 
-	baseUrl := getBaseUrl(selectedModel)
+	baseUrl := resolveBaseUrl(config, selectedModel)
+	authToken := resolveAuthToken(config, selectedModel)
 
 	// Set environment variables for the current process immediately
-	os.Setenv("ANTHROPIC_AUTH_TOKEN", selectedModel.ApiKey)
+	os.Setenv("ANTHROPIC_AUTH_TOKEN", authToken)
 	os.Setenv("ANTHROPIC_BASE_URL", baseUrl)
 
 	// Set persistent environment variables on Windows in a goroutine because setx is slow
 	go func() {
-		cmd1 := exec.Command("setx", "ANTHROPIC_AUTH_TOKEN", selectedModel.ApiKey)
+		cmd1 := exec.Command("setx", "ANTHROPIC_AUTH_TOKEN", authToken)
 		cmd1.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 		cmd1.Run()
 
@@ -396,3 +427,20 @@ func (a *App) syncToSystemEnv(config AppConfig) {
 		cmd2.Run()
 	}()
 }
+
+func (a *App) showNotification(title, message string) {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, "'", "''")
+	}
+	// Toast via the WinRT API; PowerShell's AppUserModelID is always registered
+	script := fmt.Sprintf(`[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$texts = $template.GetElementsByTagName('text')
+$texts.Item(0).AppendChild($template.CreateTextNode('%s')) > $null
+$texts.Item(1).AppendChild($template.CreateTextNode('%s')) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show($toast)`, escape(title), escape(message))
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	go cmd.Run()
+}
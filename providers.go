@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// CompatProfile describes the request features a provider rejects and how
+// the gateway adapts requests before forwarding them.
+type CompatProfile struct {
+	DropHeaders       []string `json:"drop_headers"`        // Request headers to remove, e.g. "anthropic-beta"
+	RemoveFields      []string `json:"remove_fields"`       // Top-level body fields to remove, e.g. "metadata"
+	StripCacheControl bool     `json:"strip_cache_control"` // Remove cache_control from system, tools and content blocks
+	DowngradeThinking bool     `json:"downgrade_thinking"`  // Drop the thinking parameter and thinking blocks from history
+
+	EmulateCountTokens bool    `json:"emulate_count_tokens"` // Answer /v1/messages/count_tokens locally
+	TokenFactor        float64 `json:"token_factor"`         // Correction applied to the local estimate, 1 when unset
+}
+
+// providerPreset holds everything cceasy knows about a built-in provider.
+type providerPreset struct {
+	BaseUrl     string            // Anthropic-compatible endpoint
+	ModelId     string            // Served for every Claude Code tier unless the model sets model_id
+	Env         map[string]string // Extra variables for Claude Code's settings.json
+	DefaultMode string            // Claude Code permissions.defaultMode, if the provider needs one
+	Compat      CompatProfile
+	Balance     BalanceFetcher // nil when the provider has no balance API
+}
+
+// Settings and profiles known to work with each built-in provider's
+// Anthropic endpoint.
+var providerPresets = map[string]providerPreset{
+	"kimi": {
+		BaseUrl: "https://api.kimi.com/coding",
+		ModelId: "kimi-k2-thinking",
+		Compat: CompatProfile{
+			DropHeaders: []string{"anthropic-beta"},
+		},
+		Balance: jsonBalanceFetcher{source: BalanceSource{
+			Url:      "https://api.moonshot.cn/v1/users/me/balance",
+			Field:    "data.available_balance",
+			Currency: "CNY",
+		}},
+	},
+	"glm": {
+		BaseUrl:     "https://open.bigmodel.cn/api/anthropic",
+		ModelId:     "glm-4.7",
+		DefaultMode: "dontAsk",
+		Compat: CompatProfile{
+			DropHeaders:       []string{"anthropic-beta"},
+			StripCacheControl: true,
+
+			EmulateCountTokens: true,
+			TokenFactor:        1.1,
+		},
+	},
+	"doubao": {
+		BaseUrl: "https://ark.cn-beijing.volces.com/api/coding",
+		ModelId: "doubao-seed-code-preview-latest",
+		Compat: CompatProfile{
+			DropHeaders:       []string{"anthropic-beta"},
+			RemoveFields:      []string{"metadata"},
+			StripCacheControl: true,
+			DowngradeThinking: true,
+
+			EmulateCountTokens: true,
+			TokenFactor:        1.15,
+		},
+	},
+	"minimax": {
+		BaseUrl: "https://api.minimaxi.com/anthropic",
+		ModelId: "MiniMax-M2.1",
+		Env: map[string]string{
+			"API_TIMEOUT_MS": "3000000",
+			"CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC": "1",
+		},
+		Compat: CompatProfile{
+			DropHeaders:       []string{"anthropic-beta"},
+			RemoveFields:      []string{"metadata"},
+			StripCacheControl: true,
+
+			EmulateCountTokens: true,
+			TokenFactor:        1.05,
+		},
+	},
+}
+
+// getProviderPreset looks up a built-in provider by model name.
+func getProviderPreset(selectedModel *ModelConfig) (providerPreset, bool) {
+	name := strings.ToLower(selectedModel.ModelName)
+	if name == "glm-4.7" {
+		name = "glm"
+	}
+	preset, ok := providerPresets[name]
+	return preset, ok
+}
+
+// compatProfile returns the model's own profile when set, otherwise the one
+// from its provider preset.
+func (m ModelConfig) compatProfile() CompatProfile {
+	if m.Compat != nil {
+		return *m.Compat
+	}
+	preset, _ := getProviderPreset(&m)
+	return preset.Compat
+}
+
+func (c CompatProfile) applyHeaders(h http.Header) {
+	for _, k := range c.DropHeaders {
+		h.Del(k)
+	}
+}
+
+// applyBody rewrites a Messages API request body according to the profile.
+// Bodies that are not JSON objects are returned unchanged.
+func (c CompatProfile) applyBody(body []byte) []byte {
+	if len(c.RemoveFields) == 0 && !c.StripCacheControl && !c.DowngradeThinking {
+		return body
+	}
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return body
+	}
+
+	for _, field := range c.RemoveFields {
+		delete(req, field)
+	}
+	if c.StripCacheControl {
+		for _, key := range []string{"system", "tools", "messages"} {
+			stripCacheControl(req[key])
+		}
+	}
+	if c.DowngradeThinking {
+		delete(req, "thinking")
+		if messages, ok := req["messages"].([]interface{}); ok {
+			for _, m := range messages {
+				msg, ok := m.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				blocks, ok := msg["content"].([]interface{})
+				if !ok {
+					continue
+				}
+				kept := []interface{}{}
+				for _, b := range blocks {
+					switch jsonString(jsonMap(b)["type"]) {
+					case "thinking", "redacted_thinking":
+						continue
+					}
+					kept = append(kept, b)
+				}
+				if len(kept) == 0 {
+					// An empty assistant turn is rejected too
+					kept = append(kept, map[string]interface{}{"type": "text", "text": "..."})
+				}
+				msg["content"] = kept
+			}
+		}
+	}
+
+	rewritten, err := json.Marshal(req)
+	if err != nil {
+		return body
+	}
+	return rewritten
+}
+
+func stripCacheControl(v interface{}) {
+	switch val := v.(type) {
+	case []interface{}:
+		for _, item := range val {
+			stripCacheControl(item)
+		}
+	case map[string]interface{}:
+		delete(val, "cache_control")
+		stripCacheControl(val["content"])
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProviderPresetLookup(t *testing.T) {
+	tests := []struct {
+		model   ModelConfig
+		baseUrl string
+		modelId string
+	}{
+		{ModelConfig{ModelName: "GLM", ModelUrl: "https://stale.example.com"}, "https://open.bigmodel.cn/api/anthropic", "glm-4.7"},
+		{ModelConfig{ModelName: "glm-4.7"}, "https://open.bigmodel.cn/api/anthropic", "glm-4.7"},
+		{ModelConfig{ModelName: "kimi", ModelId: "kimi-k2-turbo"}, "https://api.kimi.com/coding", "kimi-k2-turbo"},
+		{ModelConfig{ModelName: "MiniMax"}, "https://api.minimaxi.com/anthropic", "MiniMax-M2.1"},
+		{ModelConfig{ModelName: "Custom", ModelUrl: "https://api.example.com", IsCustom: true}, "https://api.example.com", "Custom"},
+		{ModelConfig{ModelName: "Custom", ModelUrl: "https://api.example.com", ModelId: "m-1", IsCustom: true}, "https://api.example.com", "m-1"},
+	}
+	for _, tt := range tests {
+		if got := getBaseUrl(&tt.model); got != tt.baseUrl {
+			t.Errorf("getBaseUrl(%s) = %q, want %q", tt.model.ModelName, got, tt.baseUrl)
+		}
+		if got := getModelId(&tt.model); got != tt.modelId {
+			t.Errorf("getModelId(%s) = %q, want %q", tt.model.ModelName, got, tt.modelId)
+		}
+	}
+}
+
+func TestSyncToClaudeSettingsUsesPreset(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	a := NewApp()
+	config := AppConfig{
+		CurrentModel: "MiniMax",
+		Models:       []ModelConfig{{ModelName: "MiniMax", ApiKey: "sk-test", ModelId: "MiniMax-M2"}},
+	}
+	if err := a.syncToClaudeSettings(config); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".claude", "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings struct {
+		Env map[string]string `json:"env"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"ANTHROPIC_AUTH_TOKEN":                     "sk-test",
+		"ANTHROPIC_BASE_URL":                       "https://api.minimaxi.com/anthropic",
+		"ANTHROPIC_MODEL":                          "MiniMax-M2",
+		"ANTHROPIC_DEFAULT_HAIKU_MODEL":            "MiniMax-M2",
+		"ANTHROPIC_DEFAULT_SONNET_MODEL":           "MiniMax-M2",
+		"ANTHROPIC_DEFAULT_OPUS_MODEL":             "MiniMax-M2",
+		"ANTHROPIC_SMALL_FAST_MODEL":               "MiniMax-M2",
+		"API_TIMEOUT_MS":                           "3000000",
+		"CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC": "1",
+	}
+	for k, v := range want {
+		if settings.Env[k] != v {
+			t.Errorf("%s = %q, want %q", k, settings.Env[k], v)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	RedactMask  = "mask"
+	RedactBlock = "block"
+)
+
+// Built-in detectors selectable by name instead of writing a pattern.
+var redactionDetectors = map[string]string{
+	"apikey": `\b(?:sk-(?:ant-)?[A-Za-z0-9_\-]{20,}|AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|xox[abprs]-[A-Za-z0-9\-]{10,}|AIza[0-9A-Za-z_\-]{35})`,
+	"jwt":    `\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`,
+	"email":  `\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`,
+}
+
+// RedactionRule masks or blocks matching text in outgoing message content.
+type RedactionRule struct {
+	Name     string `json:"name"`
+	Detector string `json:"detector"` // "apikey", "jwt" or "email"; takes precedence over Pattern
+	Pattern  string `json:"pattern"`  // Regular expression
+	Action   string `json:"action"`   // "mask" (default) or "block"
+}
+
+// RedactionEvent is one entry of a session's redaction log. The matched
+// text itself is never logged.
+type RedactionEvent struct {
+	Time      string `json:"time"`
+	RequestId string `json:"request_id"`
+	Rule      string `json:"rule"`
+	Action    string `json:"action"`
+	Matches   int    `json:"matches"`
+}
+
+type compiledRule struct {
+	name   string
+	action string
+	re     *regexp.Regexp
+}
+
+// Content block types whose text must not be touched: thinking blocks are
+// signed and images carry binary data.
+var redactionSkipBlocks = map[string]bool{
+	"thinking":          true,
+	"redacted_thinking": true,
+	"image":             true,
+	"document":          true,
+}
+
+// Fields inside content blocks that are identifiers rather than content.
+var redactionSkipFields = map[string]bool{
+	"type":          true,
+	"id":            true,
+	"tool_use_id":   true,
+	"name":          true,
+	"role":          true,
+	"cache_control": true,
+}
+
+// redactionRules returns the global rules followed by those of the current
+// project.
+func redactionRules(config AppConfig) []RedactionRule {
+	rules := append([]RedactionRule{}, config.Gateway.Redactions...)
+	for _, p := range config.Projects {
+		if p.Id == config.CurrentProject {
+			rules = append(rules, p.Redactions...)
+			break
+		}
+	}
+	return rules
+}
+
+// validateRedactionRules compiles the rules the gateway would use for every
+// project, so a bad pattern or a clashing name is rejected when the config
+// is saved instead of failing every request.
+func validateRedactionRules(config AppConfig) error {
+	if _, err := compileRedactionRules(config.Gateway.Redactions); err != nil {
+		return err
+	}
+	for _, p := range config.Projects {
+		projectConfig := config
+		projectConfig.CurrentProject = p.Id
+		if _, err := compileRedactionRules(redactionRules(projectConfig)); err != nil {
+			return fmt.Errorf("project %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func compileRedactionRules(rules []RedactionRule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	seen := make(map[string]bool)
+	for i, r := range rules {
+		pattern := r.Pattern
+		name := r.Name
+		if r.Detector != "" {
+			p, ok := redactionDetectors[r.Detector]
+			if !ok {
+				return nil, fmt.Errorf("unknown redaction detector %q", r.Detector)
+			}
+			pattern = p
+			if name == "" {
+				name = r.Detector
+			}
+		}
+		if pattern == "" {
+			continue
+		}
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+		// Matches are counted and logged by name
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate redaction rule name %q", name)
+		}
+		seen[name] = true
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %v", name, err)
+		}
+		action := r.Action
+		switch action {
+		case "":
+			action = RedactMask
+		case RedactMask, RedactBlock:
+		default:
+			return nil, fmt.Errorf("invalid action %q in redaction rule %q", r.Action, name)
+		}
+		compiled = append(compiled, compiledRule{name: name, action: action, re: re})
+	}
+	return compiled, nil
+}
+
+// redactRequest applies the rules to the system prompt and message content
+// of a request body. It returns the rewritten body, the per-rule match
+// counts, and the name of the first blocking rule that matched, if any.
+//
+// Blocking rules only block on the latest message. Text that was blocked
+// once stays in the conversation history, so earlier matches are masked
+// instead; otherwise every later request of the session would be blocked.
+func redactRequest(rules []compiledRule, body []byte) ([]byte, map[string]int, string) {
+	var req map[string]interface{}
+	if len(rules) == 0 || json.Unmarshal(body, &req) != nil {
+		return body, nil, ""
+	}
+
+	counts := make(map[string]int)
+	blocked := make(map[string]bool)
+	var redact func(v interface{}, latest bool) interface{}
+	redact = func(v interface{}, latest bool) interface{} {
+		switch val := v.(type) {
+		case string:
+			for _, rule := range rules {
+				matches := len(rule.re.FindAllStringIndex(val, -1))
+				if matches == 0 {
+					continue
+				}
+				counts[rule.name] += matches
+				if rule.action == RedactBlock && latest {
+					blocked[rule.name] = true
+					continue
+				}
+				val = rule.re.ReplaceAllLiteralString(val, "[REDACTED:"+rule.name+"]")
+			}
+			return val
+		case []interface{}:
+			for i, item := range val {
+				val[i] = redact(item, latest)
+			}
+			return val
+		case map[string]interface{}:
+			if redactionSkipBlocks[jsonString(val["type"])] {
+				return val
+			}
+			for k, item := range val {
+				if !redactionSkipFields[k] {
+					val[k] = redact(item, latest)
+				}
+			}
+			return val
+		}
+		return v
+	}
+
+	if system, ok := req["system"]; ok {
+		req["system"] = redact(system, false)
+	}
+	if messages, ok := req["messages"].([]interface{}); ok {
+		for i, m := range messages {
+			if msg, ok := m.(map[string]interface{}); ok {
+				msg["content"] = redact(msg["content"], i == len(messages)-1)
+			}
+		}
+	}
+	if len(counts) == 0 {
+		return body, nil, ""
+	}
+
+	for _, rule := range rules {
+		if blocked[rule.name] {
+			return body, counts, rule.name
+		}
+	}
+	rewritten, err := json.Marshal(req)
+	if err != nil {
+		return body, counts, ""
+	}
+	return rewritten, counts, ""
+}
+
+func getRedactionDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cceasy", "redactions"), nil
+}
+
+func redactionLogPath(session string) (string, error) {
+	dir, err := getRedactionDir()
+	if err != nil {
+		return "", err
+	}
+	name := captureNamePattern.ReplaceAllString(session, "_")
+	if name == "" {
+		return "", fmt.Errorf("invalid session id")
+	}
+	return filepath.Join(dir, name+".jsonl"), nil
+}
+
+type redactionLog struct {
+	mu sync.Mutex
+}
+
+func (l *redactionLog) write(session, requestId string, rules []compiledRule, counts map[string]int) error {
+	path, err := redactionLogPath(session)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	now := time.Now().Format(time.RFC3339)
+	for _, rule := range rules {
+		if counts[rule.name] == 0 {
+			continue
+		}
+		line, err := json.Marshal(RedactionEvent{
+			Time:      now,
+			RequestId: requestId,
+			Rule:      rule.name,
+			Action:    rule.action,
+			Matches:   counts[rule.name],
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadRedactionLog returns what the gateway redacted or blocked in a session.
+func (a *App) LoadRedactionLog(session string) ([]RedactionEvent, error) {
+	path, err := redactionLogPath(session)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []RedactionEvent{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events := []RedactionEvent{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e RedactionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRedactionRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		global  []RedactionRule
+		project []RedactionRule
+		wantErr string
+	}{
+		{"valid", []RedactionRule{{Detector: "apikey"}, {Name: "id", Pattern: `CUST-\d+`, Action: RedactBlock}}, nil, ""},
+		{"bad regex", []RedactionRule{{Name: "broken", Pattern: `([a-z`}}, nil, "invalid redaction rule"},
+		{"unknown detector", []RedactionRule{{Detector: "ssn"}}, nil, "unknown redaction detector"},
+		{"unknown action", []RedactionRule{{Name: "id", Pattern: "x", Action: "drop"}}, nil, "invalid action"},
+		{"duplicate name", []RedactionRule{{Name: "id", Pattern: "a"}, {Name: "id", Pattern: "b"}}, nil, "duplicate"},
+		{"duplicate detector", []RedactionRule{{Detector: "email"}, {Detector: "email", Action: RedactBlock}}, nil, "duplicate"},
+		{"clash with project", []RedactionRule{{Name: "id", Pattern: "a"}}, []RedactionRule{{Name: "id", Pattern: "b"}}, `project "P"`},
+		{"bad project regex", nil, []RedactionRule{{Name: "p", Pattern: `*`}}, `project "P"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := AppConfig{
+				Gateway:  GatewayConfig{Redactions: tt.global},
+				Projects: []ProjectConfig{{Id: "p1", Name: "P", Redactions: tt.project}},
+			}
+			err := validateRedactionRules(config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedactRequestBlocksOnlyLatestMessage(t *testing.T) {
+	rules, err := compileRedactionRules([]RedactionRule{
+		{Name: "customer", Pattern: `CUST-[0-9]{6}`, Action: RedactBlock},
+		{Detector: "email"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"system":"mail ops@example.com","messages":[{"role":"user","content":"look up CUST-123456"}]}`
+	_, counts, blockedBy := redactRequest(rules, []byte(body))
+	if blockedBy != "customer" || counts["customer"] != 1 || counts["email"] != 1 {
+		t.Fatalf("blockedBy = %q, counts = %v", blockedBy, counts)
+	}
+
+	// The blocked text is still in the history when the user continues
+	body = `{"messages":[{"role":"user","content":"look up CUST-123456"},{"role":"user","content":"never mind, use ops@example.com"}]}`
+	out, counts, blockedBy := redactRequest(rules, []byte(body))
+	if blockedBy != "" {
+		t.Fatalf("request blocked by %q because of earlier history", blockedBy)
+	}
+	if strings.Contains(string(out), "CUST-123456") || strings.Contains(string(out), "ops@example.com") {
+		t.Fatalf("matches left in %s", out)
+	}
+	if !strings.Contains(string(out), "[REDACTED:customer]") || counts["customer"] != 1 {
+		t.Fatalf("history not masked: %s %v", out, counts)
+	}
+}
+
+func TestRedactionActivatesGateway(t *testing.T) {
+	config := AppConfig{
+		CurrentModel: "GLM",
+		Models:       []ModelConfig{{ModelName: "GLM", ApiKey: "k"}},
+		Projects:     []ProjectConfig{{Id: "p1", Name: "P"}, {Id: "p2", Name: "Q"}},
+	}
+	if gatewayActive(config) {
+		t.Fatal("gateway active without any reason")
+	}
+
+	global := config
+	global.Gateway.Redactions = []RedactionRule{{Detector: "apikey"}}
+	if !gatewayActive(global) {
+		t.Error("global redaction rules bypass the gateway")
+	}
+
+	project := config
+	project.Projects = []ProjectConfig{{Id: "p1", Name: "P", Redactions: []RedactionRule{{Detector: "email"}}}, {Id: "p2", Name: "Q"}}
+	project.CurrentProject = "p1"
+	if !gatewayActive(project) {
+		t.Error("project redaction rules bypass the gateway")
+	}
+	project.CurrentProject = "p2"
+	if gatewayActive(project) {
+		t.Error("another project's rules activate the gateway")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Model tiers Claude Code distinguishes between.
+var modelTiers = []string{"haiku", "sonnet", "opus"}
+
+// TierRoute sends requests for one model tier to a different ModelConfig.
+type TierRoute struct {
+	Tier        string `json:"tier"`         // "haiku", "sonnet" or "opus"
+	Model       string `json:"model"`        // ModelName of the target ModelConfig
+	TargetModel string `json:"target_model"` // Optional model id override for the target provider
+}
+
+// tierOf classifies a requested model id such as "claude-3-5-haiku-20241022"
+// or the "cceasy-haiku" placeholder written to settings.json.
+func tierOf(model string) string {
+	lower := strings.ToLower(model)
+	for _, tier := range modelTiers {
+		if strings.Contains(lower, tier) {
+			return tier
+		}
+	}
+	return ""
+}
+
+// tierPlaceholder is the model id Claude Code is told to use for a tier
+// when routing is on, so the gateway can tell the tiers apart.
+func tierPlaceholder(tier string) string {
+	return "cceasy-" + tier
+}
+
+// routeRequest picks the ModelConfig for a request according to the tier
+// routes. The body's model field is rewritten to the target provider's model
+// id whenever a route matches or the request carries a tier placeholder.
+func routeRequest(config AppConfig, defaultModel ModelConfig, body []byte) (ModelConfig, []byte) {
+	if len(config.Gateway.Routes) == 0 {
+		return defaultModel, body
+	}
+
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return defaultModel, body
+	}
+	requested := jsonString(req["model"])
+	tier := tierOf(requested)
+	if tier == "" {
+		return defaultModel, body
+	}
+
+	target := defaultModel
+	targetId := ""
+	matched := false
+	for _, route := range config.Gateway.Routes {
+		if !strings.EqualFold(route.Tier, tier) {
+			continue
+		}
+		for _, m := range config.Models {
+			if m.ModelName == route.Model {
+				target = m
+				targetId = route.TargetModel
+				matched = true
+				break
+			}
+		}
+		if matched {
+			break
+		}
+	}
+	if !matched && requested != tierPlaceholder(tier) {
+		return defaultModel, body
+	}
+
+	if targetId == "" {
+		targetId = getModelId(&target)
+	}
+	req["model"] = targetId
+	rewritten, err := json.Marshal(req)
+	if err != nil {
+		return target, body
+	}
+	return target, rewritten
+}
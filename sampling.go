@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hasSamplingOverrides reports whether the gateway has to rewrite the
+// model's requests.
+func (m ModelConfig) hasSamplingOverrides() bool {
+	return m.Temperature != nil || m.TopP != nil || m.MaxTokens > 0
+}
+
+// applySamplingOverrides sets the model's temperature and top_p on a
+// Messages request and lowers max_tokens to the model's ceiling.
+func applySamplingOverrides(m ModelConfig, body []byte) []byte {
+	if !m.hasSamplingOverrides() {
+		return body
+	}
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return body
+	}
+
+	thinking := jsonMap(req["thinking"])
+	if m.MaxTokens > 0 {
+		if max := jsonInt(req["max_tokens"]); max == 0 || max > m.MaxTokens {
+			req["max_tokens"] = m.MaxTokens
+			// The thinking budget has to stay below max_tokens
+			if jsonInt(thinking["budget_tokens"]) >= m.MaxTokens {
+				delete(req, "thinking")
+			}
+		}
+	}
+
+	// Extended thinking only accepts the default sampling parameters
+	if _, thinkingKept := req["thinking"]; !thinkingKept || jsonString(thinking["type"]) != "enabled" {
+		if m.Temperature != nil {
+			req["temperature"] = *m.Temperature
+		}
+		if m.TopP != nil {
+			req["top_p"] = *m.TopP
+		}
+	}
+
+	rewritten, err := json.Marshal(req)
+	if err != nil {
+		return body
+	}
+	return rewritten
+}
+
+// modelDefaultsFile locates model_config.json next to the executable or in
+// the working directory.
+func modelDefaultsFile() string {
+	candidates := []string{}
+	if exe, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), "model_config.json"))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		candidates = append(candidates, filepath.Join(wd, "model_config.json"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// minImportedMaxTokens is the lowest max_tokens ceiling taken from
+// model_config.json. Claude Code asks for thinking budgets up to 31999
+// tokens, and a lower ceiling would cap every answer and strip thinking.
+const minImportedMaxTokens = 32000
+
+// importModelDefaults fills in sampling parameters from model_config.json
+// for models that do not set them in the user's config. It runs once per
+// config, when it is created or first migrated.
+func importModelDefaults(config *AppConfig) {
+	config.ModelDefaultsImported = true
+	path := modelDefaultsFile()
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var defaults struct {
+		Models []struct {
+			ModelName   string   `json:"model_name"`
+			Temperature *float64 `json:"temperature"`
+			TopP        *float64 `json:"top_p"`
+			MaxTokens   int      `json:"max_tokens"`
+		} `json:"models"`
+	}
+	if json.Unmarshal(data, &defaults) != nil {
+		return
+	}
+
+	for i := range config.Models {
+		m := &config.Models[i]
+		for _, d := range defaults.Models {
+			if !strings.EqualFold(d.ModelName, m.ModelName) {
+				continue
+			}
+			if m.Temperature == nil {
+				m.Temperature = d.Temperature
+			}
+			if m.TopP == nil {
+				m.TopP = d.TopP
+			}
+			if m.MaxTokens == 0 && d.MaxTokens >= minImportedMaxTokens {
+				m.MaxTokens = d.MaxTokens
+			}
+			break
+		}
+	}
+}
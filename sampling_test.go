@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportModelDefaultsOnce(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".claude_model_config.json")
+
+	// A config saved before model_config.json was imported, with a ceiling
+	// the user chose
+	existing := AppConfig{
+		CurrentModel: "GLM",
+		Models:       []ModelConfig{{ModelName: "GLM", ApiKey: "k", MaxTokens: 1000}, {ModelName: "MiniMax"}, {ModelName: "Custom", IsCustom: true}},
+	}
+	data, _ := json.Marshal(existing)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	config, err := app.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	glm := config.Models[0]
+	if !config.ModelDefaultsImported {
+		t.Fatal("import marker not set")
+	}
+	if glm.MaxTokens != 1000 {
+		t.Errorf("max_tokens = %d, want the user's 1000 kept", glm.MaxTokens)
+	}
+	if glm.Temperature == nil || *glm.Temperature != 0.7 {
+		t.Errorf("temperature = %v, want 0.7 from model_config.json", glm.Temperature)
+	}
+
+	// The marker is persisted, so a value the user clears stays cleared
+	var saved AppConfig
+	data, _ = os.ReadFile(path)
+	if err := json.Unmarshal(data, &saved); err != nil || !saved.ModelDefaultsImported {
+		t.Fatalf("marker not persisted: %v", err)
+	}
+	saved.Models[0].Temperature = nil
+	data, _ = json.Marshal(saved)
+	os.WriteFile(path, data, 0644)
+	config, err = app.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Models[0].Temperature != nil {
+		t.Errorf("defaults imported again: temperature = %v", *config.Models[0].Temperature)
+	}
+	if config.Models[0].MaxTokens != 1000 {
+		t.Errorf("max_tokens = %d after reload, want 1000", config.Models[0].MaxTokens)
+	}
+}
+
+func TestSamplingOverridesActivateGateway(t *testing.T) {
+	temperature := 0.6
+	for _, m := range []ModelConfig{
+		{ModelName: "GLM", ApiKey: "k", Temperature: &temperature},
+		{ModelName: "GLM", ApiKey: "k", TopP: &temperature},
+		{ModelName: "GLM", ApiKey: "k", MaxTokens: 16000},
+	} {
+		config := AppConfig{CurrentModel: "GLM", Models: []ModelConfig{m}}
+		if !gatewayActive(config) {
+			t.Errorf("overrides of %+v bypass the gateway", m)
+		}
+	}
+	if gatewayActive(AppConfig{CurrentModel: "GLM", Models: []ModelConfig{{ModelName: "GLM", ApiKey: "k"}}}) {
+		t.Error("gateway active without overrides")
+	}
+}
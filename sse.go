@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type sseEvent struct {
+	Event string `json:"event"`
+	Data  string `json:"data"`
+}
+
+// sseDecoder incrementally splits a text/event-stream body into events.
+// Chunks may end anywhere, so partial lines are buffered between writes.
+type sseDecoder struct {
+	buf     []byte
+	event   string
+	data    []string
+	onEvent func(sseEvent)
+}
+
+func (d *sseDecoder) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+	for {
+		i := bytes.IndexByte(d.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSuffix(string(d.buf[:i]), "\r")
+		d.buf = d.buf[i+1:]
+		d.line(line)
+	}
+	return len(p), nil
+}
+
+// Close dispatches any event left without a trailing blank line.
+func (d *sseDecoder) Close() {
+	if len(d.buf) > 0 {
+		d.line(strings.TrimSuffix(string(d.buf), "\r"))
+		d.buf = nil
+	}
+	d.dispatch()
+}
+
+func (d *sseDecoder) line(line string) {
+	switch {
+	case line == "":
+		d.dispatch()
+	case strings.HasPrefix(line, ":"):
+		// Comment / keep-alive
+	case strings.HasPrefix(line, "event:"):
+		d.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+	case strings.HasPrefix(line, "data:"):
+		data := strings.TrimPrefix(line, "data:")
+		d.data = append(d.data, strings.TrimPrefix(data, " "))
+	}
+}
+
+func (d *sseDecoder) dispatch() {
+	if d.event == "" && len(d.data) == 0 {
+		return
+	}
+	ev := sseEvent{Event: d.event, Data: strings.Join(d.data, "\n")}
+	d.event = ""
+	d.data = nil
+	if d.onEvent != nil {
+		d.onEvent(ev)
+	}
+}
+
+// encodeSSE serializes a single event in text/event-stream format.
+func encodeSSE(ev sseEvent) []byte {
+	var b bytes.Buffer
+	if ev.Event != "" {
+		b.WriteString("event: " + ev.Event + "\n")
+	}
+	for _, line := range strings.Split(ev.Data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// reassembleMessage rebuilds the final Messages API response object from
+// the events of a streamed response.
+func reassembleMessage(events []sseEvent) (map[string]interface{}, error) {
+	var message map[string]interface{}
+	var content []map[string]interface{}
+	partialJson := make(map[int]*strings.Builder)
+
+	for _, ev := range events {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(ev.Data), &data); err != nil {
+			continue
+		}
+		eventType, _ := data["type"].(string)
+		switch eventType {
+		case "message_start":
+			message, _ = data["message"].(map[string]interface{})
+		case "content_block_start":
+			index := jsonInt(data["index"])
+			block, _ := data["content_block"].(map[string]interface{})
+			for len(content) <= index {
+				content = append(content, nil)
+			}
+			content[index] = block
+		case "content_block_delta":
+			index := jsonInt(data["index"])
+			if index >= len(content) || content[index] == nil {
+				continue
+			}
+			block := content[index]
+			delta, _ := data["delta"].(map[string]interface{})
+			switch delta["type"] {
+			case "text_delta":
+				block["text"] = jsonString(block["text"]) + jsonString(delta["text"])
+			case "thinking_delta":
+				block["thinking"] = jsonString(block["thinking"]) + jsonString(delta["thinking"])
+			case "signature_delta":
+				block["signature"] = jsonString(block["signature"]) + jsonString(delta["signature"])
+			case "input_json_delta":
+				if partialJson[index] == nil {
+					partialJson[index] = &strings.Builder{}
+				}
+				partialJson[index].WriteString(jsonString(delta["partial_json"]))
+			}
+		case "content_block_stop":
+			index := jsonInt(data["index"])
+			if sb, ok := partialJson[index]; ok && index < len(content) && content[index] != nil {
+				var input interface{}
+				if sb.Len() == 0 {
+					input = map[string]interface{}{}
+				} else if err := json.Unmarshal([]byte(sb.String()), &input); err != nil {
+					input = sb.String()
+				}
+				content[index]["input"] = input
+			}
+		case "message_delta":
+			if message == nil {
+				continue
+			}
+			if delta, ok := data["delta"].(map[string]interface{}); ok {
+				for k, v := range delta {
+					message[k] = v
+				}
+			}
+			if usage, ok := data["usage"].(map[string]interface{}); ok {
+				merged, _ := message["usage"].(map[string]interface{})
+				if merged == nil {
+					merged = make(map[string]interface{})
+				}
+				for k, v := range usage {
+					merged[k] = v
+				}
+				message["usage"] = merged
+			}
+		case "error":
+			return data, nil
+		}
+	}
+
+	if message == nil {
+		return nil, fmt.Errorf("stream did not contain a message_start event")
+	}
+	blocks := make([]interface{}, 0, len(content))
+	for _, block := range content {
+		if block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	message["content"] = blocks
+	return message, nil
+}
+
+func jsonInt(v interface{}) int {
+	if f, ok := v.(float64); ok {
+		return int(f)
+	}
+	return 0
+}
+
+func jsonString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func jsonMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
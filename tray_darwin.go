@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/energye/systray"
@@ -31,84 +32,115 @@ func setupTray(app *App, appOptions *options.App) {
 			// Ensure clicking the icon shows the menu immediately on macOS
 			systray.CreateMenu()
 
-			mShow := systray.AddMenuItem("Show Main Window", "Show Main Window")
-			mLaunch := systray.AddMenuItem("Launch Claude Code", "Launch Claude Code in Terminal")
-			systray.AddSeparator()
+			models := &trayModels{}
+			// Health of local model servers, hidden until one is configured
+			localStatus := &localTrayStatus{lang: "en"}
+
+			// buildMenu adds all menu items; it runs again whenever the
+			// list of models changes
+			buildMenu := func(config AppConfig) {
+				mShow := systray.AddMenuItem("Show Main Window", "Show Main Window")
+				mLaunch := systray.AddMenuItem("Launch Claude Code", "Launch Claude Code in Terminal")
+				systray.AddSeparator()
+
+				// Model menu items map
+				modelItems := make(map[string]*systray.MenuItem)
+				names := []string{}
+				for _, model := range config.Models {
+					modelName := model.ModelName
+					m := systray.AddMenuItemCheckbox(modelName, "Switch to "+modelName, modelName == config.CurrentModel)
+					modelItems[modelName] = m
+					names = append(names, modelName)
+
+					m.Click(func() {
+						go func() {
+							currentConfig, _ := app.LoadConfig()
+							// Check if target model has API key
+							for _, m := range currentConfig.Models {
+								if m.ModelName == modelName {
+									if len(m.apiKeys()) == 0 {
+										runtime.WindowShow(app.ctx)
+										return
+									}
+									break
+								}
+							}
+							currentConfig.CurrentModel = modelName
+							app.SaveConfig(currentConfig)
+						}()
+					})
+				}
+				models.set(names, modelItems)
 
-			// Model menu items map
-			modelItems := make(map[string]*systray.MenuItem)
+				mLocal := systray.AddMenuItem("", "Local model servers")
+				mLocal.Disable()
+				mLocal.Hide()
+				localStatus.setItem(mLocal)
 
-			// Load config to populate tray
-			config, _ := app.LoadConfig()
-			for _, model := range config.Models {
-				modelName := model.ModelName
-				m := systray.AddMenuItemCheckbox(modelName, "Switch to "+modelName, modelName == config.CurrentModel)
-				modelItems[modelName] = m
-				
-				m.Click(func() {
+				systray.AddSeparator()
+				mQuit := systray.AddMenuItem("Quit", "Quit Application")
+
+				// Register update function
+				UpdateTrayMenu = func(lang string) {
+					t, ok := trayTranslations[lang]
+					if !ok {
+						t = trayTranslations["en"]
+					}
+					systray.SetTooltip(t["title"])
+					mShow.SetTitle(t["show"])
+					mLaunch.SetTitle(t["launch"])
+					mQuit.SetTitle(t["quit"])
+					localStatus.setLang(lang)
+				}
+
+				// Handle menu clicks
+				mShow.Click(func() {
+					go runtime.WindowShow(app.ctx)
+				})
+
+				mLaunch.Click(func() {
 					go func() {
-						currentConfig, _ := app.LoadConfig()
-						// Check if target model has API key
-						for _, m := range currentConfig.Models {
-							if m.ModelName == modelName {
-								if m.ApiKey == "" {
-									runtime.WindowShow(app.ctx)
-									return
-								}
-								break
-							}
-						}
-						currentConfig.CurrentModel = modelName
-						app.SaveConfig(currentConfig)
+						cfg, _ := app.LoadConfig()
+						app.LaunchClaude(false, cfg.ProjectDir)
 					}()
 				})
-			}
-
-			systray.AddSeparator()
-			mQuit := systray.AddMenuItem("Quit", "Quit Application")
 
-			// Register update function
-			UpdateTrayMenu = func(lang string) {
-				t, ok := trayTranslations[lang]
-				if !ok {
-					t = trayTranslations["en"]
-				}
-				systray.SetTooltip(t["title"])
-				mShow.SetTitle(t["show"])
-				mLaunch.SetTitle(t["launch"])
-				mQuit.SetTitle(t["quit"])
+				mQuit.Click(func() {
+					go func() {
+						systray.Quit()
+						runtime.Quit(app.ctx)
+					}()
+				})
 			}
 
+			// Load config to populate tray
+			config, _ := app.LoadConfig()
+			buildMenu(config)
+			go app.watchLocalModels(localStatus.set)
+
 			// Register config change listener
+			var menuMu sync.Mutex
 			OnConfigChanged = func(cfg AppConfig) {
-				for name, item := range modelItems {
-					if name == cfg.CurrentModel {
-						item.Check()
-					} else {
-						item.Uncheck()
+				menuMu.Lock()
+				if models.changed(cfg.Models) {
+					// Models were added or removed, e.g. by local discovery
+					systray.ResetMenu()
+					buildMenu(cfg)
+					if app.CurrentLanguage != "" {
+						UpdateTrayMenu(app.CurrentLanguage)
 					}
+				} else {
+					models.check(cfg.CurrentModel)
 				}
+				menuMu.Unlock()
 				runtime.EventsEmit(app.ctx, "config-changed", cfg)
 			}
 
-			// Handle menu clicks
-			mShow.Click(func() {
-				go runtime.WindowShow(app.ctx)
-			})
-
-			mLaunch.Click(func() {
-				go func() {
-					cfg, _ := app.LoadConfig()
-					app.LaunchClaude(false, cfg.ProjectDir)
-				}()
-			})
-
-			mQuit.Click(func() {
-				go func() {
-					systray.Quit()
-					runtime.Quit(app.ctx)
-				}()
-			})
+			// Show the last key check as a coloured badge per model
+			OnKeyHealthChanged = models.setHealth
+			if results, err := app.GetKeyHealth(); err == nil {
+				OnKeyHealthChanged(modelKeyStatus(results))
+			}
 
 			// Initial language sync
 			if app.CurrentLanguage != "" {
@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/energye/systray"
@@ -25,90 +26,121 @@ func setupTray(app *App, appOptions *options.App) {
 				systray.SetTitle("Claude Config Manager")
 				systray.SetTooltip("Claude Config Manager")
 
-				mShow := systray.AddMenuItem("Show", "Show Main Window")
-				mLaunch := systray.AddMenuItem("Launch Claude Code", "Launch Claude Code in Terminal")
-				systray.AddSeparator()
+				models := &trayModels{}
+				// Health of local model servers, hidden until one is configured
+				localStatus := &localTrayStatus{lang: "en"}
 
-				// Model menu items map
-				modelItems := make(map[string]*systray.MenuItem)
+				// buildMenu adds all menu items; it runs again whenever the
+				// list of models changes
+				buildMenu := func(config AppConfig) {
+					mShow := systray.AddMenuItem("Show", "Show Main Window")
+					mLaunch := systray.AddMenuItem("Launch Claude Code", "Launch Claude Code in Terminal")
+					systray.AddSeparator()
 
-				// Load config to populate tray
-				config, _ := app.LoadConfig()
-				for _, model := range config.Models {
-					m := systray.AddMenuItemCheckbox(model.ModelName, "Switch to "+model.ModelName, model.ModelName == config.CurrentModel)
-					modelItems[model.ModelName] = m
-					
-					modelName := model.ModelName
-					m.Click(func() {
-						go func() {
-							currentConfig, _ := app.LoadConfig()
-							for _, m := range currentConfig.Models {
-								if m.ModelName == modelName {
-									if m.ApiKey == "" {
-										runtime.WindowShow(app.ctx)
-										return
+					// Model menu items map
+					modelItems := make(map[string]*systray.MenuItem)
+					names := []string{}
+					for _, model := range config.Models {
+						m := systray.AddMenuItemCheckbox(model.ModelName, "Switch to "+model.ModelName, model.ModelName == config.CurrentModel)
+						modelItems[model.ModelName] = m
+						names = append(names, model.ModelName)
+
+						modelName := model.ModelName
+						m.Click(func() {
+							go func() {
+								currentConfig, _ := app.LoadConfig()
+								for _, m := range currentConfig.Models {
+									if m.ModelName == modelName {
+										if len(m.apiKeys()) == 0 {
+											runtime.WindowShow(app.ctx)
+											return
+										}
+										break
 									}
+								}
+								currentConfig.CurrentModel = modelName
+								app.SaveConfig(currentConfig)
+							}()
+						})
+					}
+					models.set(names, modelItems)
+
+					mLocal := systray.AddMenuItem("", "Local model servers")
+					mLocal.Disable()
+					mLocal.Hide()
+					localStatus.setItem(mLocal)
+
+					systray.AddSeparator()
+					mQuit := systray.AddMenuItem("Quit", "Quit Application")
+
+					// Register update function
+					UpdateTrayMenu = func(lang string) {
+						t, ok := trayTranslations[lang]
+						if !ok {
+							t = trayTranslations["en"]
+						}
+						systray.SetTitle(t["title"])
+						systray.SetTooltip(t["title"])
+						mShow.SetTitle(t["show"])
+						mLaunch.SetTitle(t["launch"])
+						mQuit.SetTitle(t["quit"])
+						localStatus.setLang(lang)
+					}
+
+					// Handle menu clicks
+					mShow.Click(func() {
+						go runtime.WindowShow(app.ctx)
+					})
+
+					mLaunch.Click(func() {
+						go func() {
+							cfg, _ := app.LoadConfig()
+							projectPath := cfg.ProjectDir
+							for _, p := range cfg.Projects {
+								if p.Id == cfg.CurrentProject {
+									projectPath = p.Path
 									break
 								}
 							}
-							currentConfig.CurrentModel = modelName
-							app.SaveConfig(currentConfig)
+							app.LaunchClaude(false, projectPath)
+						}()
+					})
+					mQuit.Click(func() {
+						go func() {
+							systray.Quit()
+							runtime.Quit(app.ctx)
 						}()
 					})
 				}
 
-				systray.AddSeparator()
-				mQuit := systray.AddMenuItem("Quit", "Quit Application")
-
-				// Register update function
-				UpdateTrayMenu = func(lang string) {
-					t, ok := trayTranslations[lang]
-					if !ok {
-						t = trayTranslations["en"]
-					}
-					systray.SetTitle(t["title"])
-					systray.SetTooltip(t["title"])
-					mShow.SetTitle(t["show"])
-					mLaunch.SetTitle(t["launch"])
-					mQuit.SetTitle(t["quit"])
-				}
+				// Load config to populate tray
+				config, _ := app.LoadConfig()
+				buildMenu(config)
+				go app.watchLocalModels(localStatus.set)
 
 				// Register config change listener
+				var menuMu sync.Mutex
 				OnConfigChanged = func(cfg AppConfig) {
-					for name, item := range modelItems {
-						if name == cfg.CurrentModel {
-							item.Check()
-						} else {
-							item.Uncheck()
+					menuMu.Lock()
+					if models.changed(cfg.Models) {
+						// Models were added or removed, e.g. by local discovery
+						systray.ResetMenu()
+						buildMenu(cfg)
+						if app.CurrentLanguage != "" {
+							UpdateTrayMenu(app.CurrentLanguage)
 						}
+					} else {
+						models.check(cfg.CurrentModel)
 					}
+					menuMu.Unlock()
 					runtime.EventsEmit(app.ctx, "config-changed", cfg)
 				}
 
-				// Handle menu clicks
-				mShow.Click(func() {
-					go runtime.WindowShow(app.ctx)
-				})
-
-							mLaunch.Click(func() {
-								go func() {
-									cfg, _ := app.LoadConfig()
-									projectPath := cfg.ProjectDir
-									for _, p := range cfg.Projects {
-										if p.Id == cfg.CurrentProject {
-											projectPath = p.Path
-											break
-										}
-									}
-									app.LaunchClaude(false, projectPath)
-								}()
-							})
-				mQuit.Click(func() {
-					go func() {
-						systray.Quit()
-						runtime.Quit(app.ctx)
-					}()
-				})
+				// Show the last key check as a coloured badge per model
+				OnKeyHealthChanged = models.setHealth
+				if results, err := app.GetKeyHealth(); err == nil {
+					OnKeyHealthChanged(modelKeyStatus(results))
+				}
 
 				if app.CurrentLanguage != "" {
 					go func() {
@@ -121,4 +153,4 @@ func setupTray(app *App, appOptions *options.App) {
 			})
 		}()
 	}
-}
\ No newline at end of file
+}